@@ -0,0 +1,92 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// Call describes a single call of a function or struct method as it is passed through the middleware
+type Call struct {
+	// Target is the name of the function or struct being called, ie. "AddInt" or "MyMath"
+	Target string
+	// Method is the name of the struct method being called, empty for functions
+	Method string
+	// Args are the converted arguments the function or method is called with, including an injected context.Context
+	Args []reflect.Value
+}
+
+// Name returns the name the call is reported by, ie. "AddInt" or "MyMath.Add"
+func (c Call) Name() string {
+	if c.Method == "" {
+		return c.Target
+	}
+	return c.Target + "." + c.Method
+}
+
+// Middleware wraps every call of a function or struct method. It continues the call by calling next (optionally with altered args) and may inspect or
+// replace the results, or return without calling next at all, ie. to deny the call
+type Middleware func(call Call, next func(Call) ([]reflect.Value, error)) ([]reflect.Value, error)
+
+// App runs the targets from the command line like Fuego, wrapping the middleware around every call
+type App struct {
+	targets    interface{}
+	middleware []Middleware
+}
+
+// New creates the app for the targets, ie. fuego.New(targets).Use(logCalls).Run()
+func New(targets interface{}) *App {
+	return &App{targets: targets}
+}
+
+// Use adds the middleware to the app, the first middleware added is the outermost and sees every call first
+func (app *App) Use(middleware ...Middleware) *App {
+	app.middleware = append(app.middleware, middleware...)
+	return app
+}
+
+// Run parses the command line and calls the matching target through the middleware, the same as Fuego does
+func (app *App) Run() ([]reflect.Value, error) {
+	return run(app.targets, &options{middleware: app.middleware})
+}
+
+// Handler returns an http.Handler exposing the targets as JSON endpoints like fuego.Handler, with the middleware wrapped around every call
+func (app *App) Handler() http.Handler {
+	return newHandler(app.targets, &options{middleware: app.middleware})
+}
+
+// Tools describes the functions and struct methods of the targets as tool definitions like fuego.Tools
+func (app *App) Tools() []Tool {
+	return Tools(app.targets)
+}
+
+// CallTool executes the tool call JSON against the targets like fuego.CallTool, with the middleware wrapped around the call
+func (app *App) CallTool(call []byte) ([]interface{}, error) {
+	return callTool(app.targets, call, &options{middleware: app.middleware})
+}
+
+// call calls the function through the middleware of the options (and the --trace), the function itself is called by the innermost next
+func (opts *options) call(call Call, fn reflect.Value) ([]reflect.Value, error) {
+	next := func(call Call) ([]reflect.Value, error) {
+		return opts.invoke(call, fn)
+	}
+	if opts.trace {
+		// the trace is innermost so it sees the arguments as altered by the middleware
+		target := next
+		next = func(call Call) ([]reflect.Value, error) {
+			return opts.traceCall(call, target)
+		}
+	}
+
+	for x := len(opts.middleware) - 1; x >= 0; x-- {
+		middleware, inner := opts.middleware[x], next
+		next = func(call Call) ([]reflect.Value, error) {
+			return middleware(call, inner)
+		}
+	}
+	return next(call)
+}
@@ -0,0 +1,126 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestAppMiddleware(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	var calls []string
+	logCalls := func(name string) Middleware {
+		return func(call Call, next func(Call) ([]reflect.Value, error)) ([]reflect.Value, error) {
+			args := make([]string, len(call.Args))
+			for x, arg := range call.Args {
+				args[x] = fmt.Sprint(arg.Interface())
+			}
+			calls = append(calls, fmt.Sprintf("%v %v(%v)", name, call.Name(), strings.Join(args, ", ")))
+
+			values, err := next(call)
+			calls = append(calls, fmt.Sprintf("%v done %v", name, err))
+			return values, err
+		}
+	}
+	double := func(call Call, next func(Call) ([]reflect.Value, error)) ([]reflect.Value, error) {
+		for x, arg := range call.Args {
+			if arg.Kind() == reflect.Int {
+				call.Args[x] = reflect.ValueOf(int(arg.Int() * 2))
+			}
+		}
+		return next(call)
+	}
+	deny := func(call Call, next func(Call) ([]reflect.Value, error)) ([]reflect.Value, error) {
+		if call.Method == "Subtract" {
+			return nil, errors.New("subtracting is not allowed")
+		}
+		return next(call)
+	}
+
+	testCases := []struct {
+		Name          string
+		Targets       interface{}
+		Middleware    []Middleware
+		Args          []string
+		Expected      interface{}
+		ExpectedCalls []string
+		ExpectedError error
+	}{
+		{"Function", AddInt, []Middleware{logCalls("outer"), logCalls("inner")}, []string{"Fuego.App.Function", "3", "5"}, 8, []string{"outer AddInt(3, 5)", "inner AddInt(3, 5)", "inner done <nil>", "outer done <nil>"}, nil},
+		{"Method", &MyMath{Offset: 1}, []Middleware{logCalls("log")}, []string{"Fuego.App.Method", "Add", "2", "3"}, float64(6), []string{"log MyMath.Add(2, 3)", "log done <nil>"}, nil},
+		{"AlteredArgs", AddInt, []Middleware{double, logCalls("log")}, []string{"Fuego.App.AlteredArgs", "3", "5"}, 16, []string{"log AddInt(6, 10)", "log done <nil>"}, nil},
+		{"Denied", []interface{}{&MyMath{}}, []Middleware{deny, logCalls("log")}, []string{"Fuego.App.Denied", "MyMath.Subtract", "6", "3"}, nil, nil, errors.New("subtracting is not allowed")},
+		{"Panic", DivideInt, []Middleware{logCalls("log")}, []string{"Fuego.App.Panic", "5", "0"}, nil, []string{"log DivideInt(5, 0)", "log done the call to \"DivideInt\" panicked: runtime error: integer divide by zero"}, errors.Errorf(TargetPanicError, "DivideInt", "runtime error: integer divide by zero")},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			calls = nil
+			os.Args = testCase.Args
+			values, err := New(testCase.Targets).Use(testCase.Middleware...).Run()
+
+			if testCase.ExpectedError != nil {
+				if err == nil || !doErrorsMatch(testCase.ExpectedError, err) {
+					t.Errorf("Expected to receive the first error but instead the second error was returned: \n\t1) \"%v\"\n\t2) \"%v\"", testCase.ExpectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("Error is not expected but got %v", err)
+			} else if len(values) < 1 || !reflect.DeepEqual(values[0].Interface(), testCase.Expected) {
+				t.Errorf("expected %v but got %v", testCase.Expected, values)
+			}
+
+			if !reflect.DeepEqual(calls, testCase.ExpectedCalls) {
+				t.Errorf("expected the middleware calls %q but got %q", testCase.ExpectedCalls, calls)
+			}
+		})
+	}
+}
+
+func TestAppHandlerMiddleware(t *testing.T) {
+	var called []string
+	handler := New(AddInt).Use(func(call Call, next func(Call) ([]reflect.Value, error)) ([]reflect.Value, error) {
+		called = append(called, call.Name())
+		return next(call)
+	}).Handler()
+
+	req := httptest.NewRequest(http.MethodPost, "/AddInt", strings.NewReader("[3, 5]"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK || !reflect.DeepEqual(called, []string{"AddInt"}) {
+		t.Errorf("expected the call to pass through the middleware but got %v %q and %v", rec.Code, rec.Body.String(), called)
+	}
+}
+
+func TestAppCallToolMiddleware(t *testing.T) {
+	var called []string
+	app := New([]interface{}{AddInt, &MyMath{}}).Use(func(call Call, next func(Call) ([]reflect.Value, error)) ([]reflect.Value, error) {
+		called = append(called, call.Name())
+		return next(call)
+	})
+
+	if tools := app.Tools(); len(tools) != len(Tools([]interface{}{AddInt, &MyMath{}})) {
+		t.Errorf("expected the tools of the targets but got %+v", tools)
+	}
+
+	results, err := app.CallTool([]byte(`{"name": "MyMath_Add", "input": {"a": 1, "b": 2}}`))
+	if err != nil {
+		t.Fatalf("Error is not expected but got %v", err)
+	}
+	if !reflect.DeepEqual(results, []interface{}{float64(3)}) || !reflect.DeepEqual(called, []string{"MyMath.Add"}) {
+		t.Errorf("expected the tool call to pass through the middleware but got %v and %v", results, called)
+	}
+}
@@ -0,0 +1,93 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ArgFileReadError       = "could not read the argument file \"%v\""
+	StdinReadError         = "could not read the argument from std in"
+	ArgsFileRecursionError = "the args file \"%v\" is included more than once"
+)
+
+// stdStreamArg is the parameter (or attribute value) that is read from std in, io.Writer parameters passed it write to std out
+const stdStreamArg = "-"
+
+// expandArgValue reads the value of a parameter or attribute from std in when it is "-" or from a file when it is "@<path>", a literal leading "@" is escaped as "@@".
+// A single trailing newline is trimmed so values like numbers can be piped in, ie. echo 5 | tool AddInt - 3.
+// Args from remote callers (--serve, --jsonrpc and CallTool) are passed through literally so they can not read the files or std in of the process
+func (opts *options) expandArgValue(value string) (string, error) {
+	switch {
+	case !opts.localArgs:
+		return value, nil
+	case value == stdStreamArg:
+		data, err := io.ReadAll(opts.input())
+		if err != nil {
+			return "", errors.Wrap(err, StdinReadError)
+		}
+		return trimNewline(string(data)), nil
+	case strings.HasPrefix(value, "@@"):
+		return value[1:], nil
+	case strings.HasPrefix(value, "@") && len(value) > 1:
+		data, err := os.ReadFile(value[1:])
+		if err != nil {
+			return "", errors.Wrapf(err, ArgFileReadError, value[1:])
+		}
+		return trimNewline(string(data)), nil
+	default:
+		return value, nil
+	}
+}
+
+// readArgsFile reads the args of an --args-file (or std in for "-"), every line is split like a shell command line so args may be quoted and lines may be # comments
+func (opts *options) readArgsFile(path string) ([]string, error) {
+	var data []byte
+	var err error
+	if path == stdStreamArg {
+		data, err = io.ReadAll(opts.input())
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, ArgFileReadError, path)
+	}
+
+	var args []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lineArgs, err := splitCommandLine(scanner.Text())
+		if err != nil {
+			return nil, errors.Wrapf(err, ArgFileReadError, path)
+		}
+		args = append(args, lineArgs...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, ArgFileReadError, path)
+	}
+	return args, nil
+}
+
+// input returns the reader std in args are read from, os.Stdin unless it is set on the options
+func (opts *options) input() io.Reader {
+	if opts.stdin != nil {
+		return opts.stdin
+	}
+	return os.Stdin
+}
+
+// trimNewline trims a single trailing newline (or carriage return and newline) from the value
+func trimNewline(value string) string {
+	value = strings.TrimSuffix(value, "\n")
+	return strings.TrimSuffix(value, "\r")
+}
@@ -0,0 +1,78 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestArgValues(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	dir := t.TempDir()
+	namePath := filepath.Join(dir, "name.txt")
+	argsPath := filepath.Join(dir, "args.txt")
+	loopPath := filepath.Join(dir, "loop.txt")
+	for path, contents := range map[string]string{
+		namePath: "alice\n",
+		argsPath: "# the greeting\nGreet \"good day\"\n--Name=bob -v\n",
+		loopPath: "--args-file=" + loopPath + "\n",
+	} {
+		if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	missingPath := filepath.Join(dir, "missing")
+	_, missingErr := os.ReadFile(missingPath)
+
+	testCases := []struct {
+		Name          string
+		Targets       interface{}
+		Args          []string
+		Stdin         string
+		Expected      interface{}
+		ExpectedError error
+	}{
+		{"StdinParam", AddInt, []string{"Fuego.Args.StdinParam", "-", "3"}, "5\n", 8, nil},
+		{"StdinFlag", &Greeter{}, []string{"Fuego.Args.StdinFlag", "Greet", "hi", "--Name", "-"}, "bob\n", "hi bob x0 verbose=false query=", nil},
+		{"FileParam", &Greeter{}, []string{"Fuego.Args.FileParam", "Greet", "@" + namePath}, "", "alice  x0 verbose=false query=", nil},
+		{"FileFlag", &Greeter{}, []string{"Fuego.Args.FileFlag", "Greet", "hi", "--Name=@" + namePath}, "", "hi alice x0 verbose=false query=", nil},
+		{"EscapedAt", &Greeter{}, []string{"Fuego.Args.EscapedAt", "Greet", "@@home", "--Name=@@bob"}, "", "@home @bob x0 verbose=false query=", nil},
+		{"ArgsFile", &Greeter{}, []string{"Fuego.Args.ArgsFile", "--args-file", argsPath, "-n", "2"}, "", "good day bob x2 verbose=true query=", nil},
+		{"ArgsFileStdin", AddInt, []string{"Fuego.Args.ArgsFileStdin", "--args-file=-"}, "1 2\n", 3, nil},
+		{"MissingFile", AddInt, []string{"Fuego.Args.MissingFile", "@" + missingPath, "3"}, "", nil, errors.Wrapf(missingErr, ArgFileReadError, missingPath)},
+		{"ArgsFileRecursion", AddInt, []string{"Fuego.Args.ArgsFileRecursion", "--args-file=" + loopPath}, "", nil, errors.Errorf(ArgsFileRecursionError, loopPath)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			opts := &options{localArgs: true, stdin: strings.NewReader(testCase.Stdin)}
+			args, err := opts.parse(testCase.Args)
+			var values []reflect.Value
+			if err == nil {
+				values, err = dispatch(testCase.Targets, args, opts)
+			}
+
+			if testCase.ExpectedError != nil {
+				if err == nil || !doErrorsMatch(testCase.ExpectedError, err) {
+					t.Errorf("Expected to receive the first error but instead the second error was returned: \n\t1) \"%v\"\n\t2) \"%v\"", testCase.ExpectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("Error is not expected but got %v", err)
+			} else if len(values) != 1 || !reflect.DeepEqual(values[0].Interface(), testCase.Expected) {
+				t.Errorf("expected %v but got %v", testCase.Expected, values)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	UnterminatedQuoteError = "the command line \"%v\" has an unterminated %v quote"
+	TrailingBackslashError = "the command line \"%v\" ends with an unescaped backslash"
+)
+
+// splitCommandLine splits a single command line into args the way a shell would, honoring single quotes, double quotes, backslash escapes and # comments
+func splitCommandLine(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+
+	runes := []rune(line)
+	for x := 0; x < len(runes); x++ {
+		r := runes[x]
+
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case r == '#' && !inArg:
+			// the rest of the line is a comment
+			return args, nil
+		case r == '\\':
+			if x+1 >= len(runes) {
+				return nil, errors.Errorf(TrailingBackslashError, line)
+			}
+			x++
+			current.WriteRune(runes[x])
+			inArg = true
+		case r == '\'':
+			end := strings.IndexRune(string(runes[x+1:]), '\'')
+			if end < 0 {
+				return nil, errors.Errorf(UnterminatedQuoteError, line, "single")
+			}
+			quoted := []rune(string(runes[x+1:])[:end])
+			current.WriteString(string(quoted))
+			x += len(quoted) + 1
+			inArg = true
+		case r == '"':
+			x++
+			for ; x < len(runes) && runes[x] != '"'; x++ {
+				// within double quotes a backslash only escapes another backslash or a double quote
+				if runes[x] == '\\' && x+1 < len(runes) && (runes[x+1] == '"' || runes[x+1] == '\\') {
+					x++
+				}
+				current.WriteRune(runes[x])
+			}
+			if x >= len(runes) {
+				return nil, errors.Errorf(UnterminatedQuoteError, line, "double")
+			}
+			inArg = true
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,227 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ConfigReadError              = "could not read the config \"%v\""
+	UnsupportedConfigFormatError = "the config \"%v\" is not a JSON (.json), YAML (.yaml, .yml) or TOML (.toml) file"
+	ConfigAttributeError         = "the config \"%v\" could not set the attribute \"%v\" of \"%v\""
+	UnknownConfigAttributeError  = "the config \"%v\" has the attribute \"%v\" which does not exist for any struct"
+)
+
+// configExtensions are the config file formats fuego can read, in the order the default config files are searched for
+var configExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// applyConfig sets the struct attributes within the targets from the --config file, or the first config file found in the default search path
+// (ie. ~/.config/<tool>/config.json). Struct values are copied into pointers when a config is found so their attributes can be set.
+// Attributes that can not be set are reported and skipped so that --<attribute>=<value> flags can still be applied on top of the config.
+func applyConfig(targets interface{}, opts *options) (interface{}, error) {
+	path := opts.config
+	if path == "" {
+		path = defaultConfigPath(os.Args[0])
+		if path == "" {
+			return targets, nil
+		}
+	}
+
+	raw, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	targets = addressableTargets(targets)
+	hasStruct, keys, known := false, []string{}, map[string]bool{}
+	for _, target := range targetList(targets) {
+		structVal := reflect.ValueOf(target)
+		if _, isConstructor := target.(*constructor); isConstructor || structVal.Kind() != reflect.Ptr || structVal.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		structVal = structVal.Elem()
+		hasStruct = true
+
+		values := map[string]configValue{}
+		flattenConfig(structVal.Type(), "", "", raw, values)
+		for _, key := range sortedKeys(values) {
+			found, err := setConfigAttribute(structVal, values[key].name, values[key].value)
+			if err != nil {
+				printError(errors.Wrapf(err, ConfigAttributeError, path, values[key].name, structVal.Type().Name()))
+			}
+			if _, ok := known[key]; !ok {
+				keys = append(keys, key)
+			}
+			known[key] = known[key] || found
+		}
+	}
+
+	// with several struct targets a config attribute only needs to exist on one of them
+	sort.Strings(keys)
+	for _, key := range keys {
+		if hasStruct && !known[key] {
+			printError(errors.Errorf(UnknownConfigAttributeError, path, key))
+		}
+	}
+	return targets, nil
+}
+
+// defaultConfigPath returns the first config file found in the default search path ~/.config/<tool>/config.<json|yaml|yml|toml>, or "" when there is none
+func defaultConfigPath(programName string) string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+
+	for _, ext := range configExtensions {
+		path := filepath.Join(configDir, filepath.Base(programName), "config"+ext)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// loadConfig reads the config file, detecting the format from the extension, into a map of the (possibly nested) config values.
+// JSON numbers are kept as json.Number so integers beyond the precision of a float64 (ie. 9007199254740993) are not rounded
+func loadConfig(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, ConfigReadError, path)
+	}
+
+	raw := map[string]interface{}{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = decodeJSONConfig(data, &raw)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, errors.Errorf(UnsupportedConfigFormatError, path)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, ConfigReadError, path)
+	}
+	return raw, nil
+}
+
+// decodeJSONConfig decodes the JSON config with numbers as json.Number, the data is checked by json.Unmarshal first
+// so invalid and trailing data are reported the same way as by the other JSON inputs
+func decodeJSONConfig(data []byte, raw *map[string]interface{}) error {
+	var check json.RawMessage
+	if err := json.Unmarshal(data, &check); err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(raw)
+}
+
+// configValue is a flattened config value and the name of the struct attribute it sets
+type configValue struct {
+	name  string
+	value interface{}
+}
+
+// flattenConfig flattens the nested maps and arrays of the config into the attribute names of the struct, keyed by the dotted config path.
+// Nested tables / objects are dotted attribute names, ie. {"DB": {"Host": "x"}} becomes "DB.Host", unless the attribute is a map
+// where the keys are indexed, ie. {"Labels": {"env": "prod"}} becomes "Labels[env]". Arrays become indexed attribute names, ie. {"Servers": [{"Host": "x"}]} becomes "Servers[0].Host"
+func flattenConfig(structType reflect.Type, key string, name string, raw map[string]interface{}, values map[string]configValue) {
+	isMap := false
+	if t, ok := attributeType(structType, name, true); ok && name != "" {
+		isMap = indirectType(t).Kind() == reflect.Map
+	}
+
+	for rawKey, val := range raw {
+		switch {
+		case name == "":
+			flattenConfigValue(structType, rawKey, rawKey, val, values)
+		case isMap:
+			flattenConfigValue(structType, key+"."+rawKey, name+"["+rawKey+"]", val, values)
+		default:
+			flattenConfigValue(structType, key+"."+rawKey, name+"."+rawKey, val, values)
+		}
+	}
+}
+
+// flattenConfigValue adds the config value under the attribute name, descending into maps and arrays (TOML decodes arrays of tables as []map[string]interface{})
+func flattenConfigValue(structType reflect.Type, key string, name string, val interface{}, values map[string]configValue) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		flattenConfig(structType, key, name, v, values)
+	case []interface{}:
+		for x, elem := range v {
+			index := "[" + strconv.Itoa(x) + "]"
+			flattenConfigValue(structType, key+index, name+index, elem, values)
+		}
+	case []map[string]interface{}:
+		for x, elem := range v {
+			index := "[" + strconv.Itoa(x) + "]"
+			flattenConfig(structType, key+index, name+index, elem, values)
+		}
+	default:
+		values[key] = configValue{name: name, value: val}
+	}
+}
+
+// setConfigAttribute sets the struct attribute from the config value, matching the flag or go field name of the attribute (or its dotted path) without regard to case.
+// It reports whether the struct has the attribute, so unknown attributes can be told apart from values that could not be converted
+func setConfigAttribute(structVal reflect.Value, name string, value interface{}) (bool, error) {
+	if value == nil {
+		_, found := attributeType(structVal.Type(), name, true)
+		return found, nil
+	}
+	return setAttribute(structVal, name, true, configString(value))
+}
+
+// configString converts a decoded config value into the string form fuego converts args from, JSON numbers keep their text
+// and floats are written without an exponent (ie. 1000000 rather than 1e+06 for an int attribute)
+func configString(value interface{}) string {
+	switch v := value.(type) {
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 32)
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// targetList returns the targets as a list, whether a single target or a slice of targets was passed in
+func targetList(targets interface{}) []interface{} {
+	if list, ok := targets.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{targets}
+}
+
+// sortedKeys returns the keys of the map in order so attributes are set (and errors reported) consistently
+func sortedKeys(values map[string]configValue) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,98 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestConfig(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	dir := t.TempDir()
+	configs := map[string]string{
+		"config.json": `{"offset": 2.5}`,
+		"config.yaml": "Offset: 3\n",
+		"config.yml":  "# comment\noffset: 4\n",
+		"config.toml": "Offset = 5.0\n",
+		"config.ini":  "Offset=6",
+		"bad.json":    `{"Offset": "hi"}`,
+		"broken.json": `{"Offset": `,
+	}
+	for name, contents := range configs {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		Name          string
+		Targets       interface{}
+		Args          []string
+		Expected      interface{}
+		ExpectedError error
+	}{
+		{"JSON", MyMath{}, []string{"Fuego.Config.JSON", "Add", "1", "2", "--config=" + filepath.Join(dir, "config.json")}, float64(5.5), nil},
+		{"YAML", &MyMath{}, []string{"Fuego.Config.YAML", "Add", "1", "2", "--config", filepath.Join(dir, "config.yaml")}, float64(6), nil},
+		{"YML", []interface{}{AddInt, MyMath{}}, []string{"Fuego.Config.YML", "MyMath.Add", "1", "2", "--config=" + filepath.Join(dir, "config.yml")}, float64(7), nil},
+		{"TOML", MyMath{}, []string{"Fuego.Config.TOML", "Add", "1", "2", "--config=" + filepath.Join(dir, "config.toml")}, float64(8), nil},
+		{"FlagsOverrideConfig", &MyMath{}, []string{"Fuego.Config.FlagsOverrideConfig", "Add", "1", "2", "--Offset=10", "--config=" + filepath.Join(dir, "config.toml")}, float64(13), nil},
+		{"InvalidValueSkipped", &MyMath{Offset: 1}, []string{"Fuego.Config.InvalidValueSkipped", "Add", "1", "2", "--config=" + filepath.Join(dir, "bad.json")}, float64(4), nil},
+		{"UnsupportedFormat", MyMath{}, []string{"Fuego.Config.UnsupportedFormat", "Add", "1", "2", "--config=" + filepath.Join(dir, "config.ini")}, nil, errors.Errorf(UnsupportedConfigFormatError, filepath.Join(dir, "config.ini"))},
+		{"MissingFile", MyMath{}, []string{"Fuego.Config.MissingFile", "Add", "1", "2", "--config=" + filepath.Join(dir, "missing.json")}, nil, errors.Errorf(ConfigReadError+": %v", filepath.Join(dir, "missing.json"), "open "+filepath.Join(dir, "missing.json")+": no such file or directory")},
+		{"InvalidFile", MyMath{}, []string{"Fuego.Config.InvalidFile", "Add", "1", "2", "--config=" + filepath.Join(dir, "broken.json")}, nil, errors.Errorf(ConfigReadError+": %v", filepath.Join(dir, "broken.json"), "unexpected end of JSON input")},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			os.Args = testCase.Args
+			values, err := Fuego(testCase.Targets)
+
+			if testCase.ExpectedError != nil {
+				if err == nil || !doErrorsMatch(testCase.ExpectedError, err) {
+					t.Errorf("Expected to receive the first error but instead the second error was returned: \n\t1) \"%v\"\n\t2) \"%v\"", testCase.ExpectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("Error is not expected but got %v", err)
+			} else if len(values) != 1 || !reflect.DeepEqual(values[0].Interface(), testCase.Expected) {
+				t.Errorf("expected %v but got %v", testCase.Expected, values)
+			}
+		})
+	}
+}
+
+func TestDefaultConfigPath(t *testing.T) {
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configDir)
+	t.Setenv("HOME", configDir)
+
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		t.Skip(err)
+	}
+
+	if path := defaultConfigPath("/usr/bin/mytool"); path != "" {
+		t.Errorf("expected no default config but found %q", path)
+	}
+
+	expected := filepath.Join(userConfigDir, "mytool", "config.yaml")
+	if err := os.MkdirAll(filepath.Dir(expected), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(expected, []byte("offset: 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if path := defaultConfigPath("/usr/bin/mytool"); path != expected {
+		t.Errorf("expected the default config %q but found %q", expected, path)
+	}
+}
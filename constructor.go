@@ -0,0 +1,146 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	InvalidConstructorError = "the constructor \"%v\" must return a struct (or pointer to a struct) optionally followed by an error"
+	ConstructorFailedError  = "the constructor \"%v\" failed"
+	DuplicateParamError     = "the constructor \"%v\" has more than one parameter named \"%v\", the parameters are parsed from flags so they must have distinct names and struct types"
+)
+
+// constructor is a struct target that is built by calling a constructor function, ie. NewClient(cfg Config) (*Client, error), rather than from its zero value
+type constructor struct {
+	fn   reflect.Value
+	name string
+	// structType is the struct the constructor builds, whose methods are the commands of the target
+	structType reflect.Type
+	// paramsType is a struct with an attribute for every parameter of the constructor, so the parameters are parsed from flags like struct attributes.
+	// Struct parameters are embedded so their attributes are promoted, ie. --Host for NewClient(cfg Config), while other parameters are set by name, ie. --host for NewClient(host string)
+	paramsType reflect.Type
+	// err is returned in place of calling the constructor when its parameters cannot be parsed from flags, ie. two parameters of the same struct type
+	err error
+}
+
+// Constructor registers the constructor function of a struct as a target, ie. fuego.Fuego(fuego.Constructor(NewClient)).
+// The constructor parameters are parsed from flags, the constructor is called and the method is dispatched on the returned instance.
+// A constructor error is returned rather than calling the method. Constructor panics when fn is not a function returning a struct (or pointer to a struct) optionally followed by an error,
+// parameters that cannot be told apart as flags (ie. two parameters of the same struct type) are reported as an error when the target is called
+func Constructor(fn interface{}) interface{} {
+	fnVal := reflect.ValueOf(fn)
+	if fnVal.Kind() != reflect.Func {
+		panic(errors.Errorf(InvalidConstructorError, fnVal.Type()))
+	}
+
+	name := functionName(fn)
+	fnType := fnVal.Type()
+	if fnType.NumOut() < 1 || fnType.NumOut() > 2 || (fnType.NumOut() == 2 && fnType.Out(1) != errorType) || indirectType(fnType.Out(0)).Kind() != reflect.Struct {
+		panic(errors.Errorf(InvalidConstructorError, name))
+	}
+
+	offset := 0
+	if acceptsContext(fnType) {
+		offset = 1
+	}
+
+	paramNames := describeFunc(fnVal).Params
+	fields := make([]reflect.StructField, 0, fnType.NumIn()-offset)
+	fieldNames := map[string]bool{}
+	for x := offset; x < fnType.NumIn(); x++ {
+		paramType := indirectType(fnType.In(x))
+		field := reflect.StructField{
+			Name: "Param" + strings.Title(paramNames[x]),
+			Type: paramType,
+			Tag:  reflect.StructTag(`fuego:"name=` + paramNames[x] + `"`),
+		}
+		if paramType.Kind() == reflect.Struct && paramType.Name() != "" && paramType.Name() == strings.Title(paramType.Name()) {
+			// reflect only embeds types with methods as the first field, otherwise the attributes are reached through the type name, ie. --Config.Host
+			embed := len(fields) == 0 || (paramType.NumMethod() == 0 && reflect.PtrTo(paramType).NumMethod() == 0)
+			field = reflect.StructField{Name: paramType.Name(), Type: paramType, Anonymous: embed}
+		}
+
+		// reflect.StructOf panics on duplicate field names, ie. NewPair(a Config, b Config), so the error is returned when the constructor is called instead
+		if fieldNames[field.Name] {
+			err := errors.Errorf(DuplicateParamError, name, field.Name)
+			return &constructor{fn: fnVal, name: name, structType: indirectType(fnType.Out(0)), paramsType: reflect.TypeOf(struct{}{}), err: err}
+		}
+		fieldNames[field.Name] = true
+		fields = append(fields, field)
+	}
+
+	return &constructor{fn: fnVal, name: name, structType: indirectType(fnType.Out(0)), paramsType: reflect.StructOf(fields)}
+}
+
+// fuegoConstructor is used as a helper function for dispatch() to handle constructor targets, the constructor parameter flags are pulled out of the args,
+// the constructor is called and the remaining args are dispatched on the returned instance
+func fuegoConstructor(c *constructor, args []string, opts *options) ([]reflect.Value, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	if len(args) < 2 {
+		return nil, errors.Errorf(InsufficientArgumentsError)
+	}
+
+	flags, remaining := extractFlags(c.paramsType, args[1:])
+	paramsVal := reflect.New(c.paramsType).Elem()
+	opts.tracef("constructing %v with %v", c.structType.Name(), c.name)
+	if err := setFlags(paramsVal, flags, opts); err != nil {
+		return nil, err
+	}
+
+	// the context is kept alive as long as the instance is used (ie. by a client the constructor starts with it), so it is cancelled
+	// once the method call is released rather than when the constructor returns
+	fnType := c.fn.Type()
+	params := make([]reflect.Value, 0, fnType.NumIn())
+	cancel := func() {}
+	if acceptsContext(fnType) {
+		var ctx context.Context
+		ctx, cancel = opts.newContext()
+		params = append(params, reflect.ValueOf(ctx))
+	}
+	for x := 0; x < paramsVal.NumField(); x++ {
+		param := paramsVal.Field(x)
+		if in := fnType.In(len(params)); in.Kind() == reflect.Ptr {
+			param = param.Addr()
+		}
+		params = append(params, param.Convert(fnType.In(len(params))))
+	}
+
+	for x, param := range params {
+		opts.tracef("  argument %v = %#v (%v)", x, param.Interface(), param.Type())
+	}
+	values, err := callTarget(c.name, c.fn, params)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if len(values) == 2 && !values[1].IsNil() {
+		cancel()
+		return nil, newHookError(c.name, "Constructor", values[1].Interface().(error), ConstructorFailedError)
+	}
+
+	instance := values[0]
+	if instance.Kind() == reflect.Ptr && instance.IsNil() {
+		cancel()
+		return nil, newHookError(c.name, "Constructor", nil, ConstructorFailedError)
+	}
+
+	// the method call cancels the context with its own cleanup, calls failing before the method is called (ie. an unknown method) are cancelled here
+	callOpts := *opts
+	callOpts.release = cancel
+	values, err = fuegoStruct(instance.Interface(), append([]string{args[0]}, remaining...), &callOpts)
+	if err != nil || !hasStreamResults(values) {
+		cancel()
+	}
+	return values, err
+}
@@ -0,0 +1,161 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type ClientConfig struct {
+	Host string
+	Port int `fuego:"short=p"`
+}
+
+type Client struct {
+	addr    string
+	Verbose bool
+}
+
+func NewClient(cfg ClientConfig) (*Client, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("a host is required")
+	}
+	return &Client{addr: fmt.Sprintf("%v:%v", cfg.Host, cfg.Port)}, nil
+}
+
+func NewLocalClient(port int, secure bool) Client {
+	scheme := "http"
+	if secure {
+		scheme = "https"
+	}
+	return Client{addr: fmt.Sprintf("%v://localhost:%v", scheme, port)}
+}
+
+func NewMirroredClient(primary ClientConfig, mirror ClientConfig) *Client {
+	return &Client{addr: primary.Host + "," + mirror.Host}
+}
+
+func (c *Client) Get(path string) string {
+	return fmt.Sprintf("GET %v%v verbose=%v", c.addr, path, c.Verbose)
+}
+
+type Watcher struct {
+	ctx context.Context
+}
+
+// lastWatcher is the last Watcher constructed so the test can check its context once the call is done
+var lastWatcher *Watcher
+
+func NewWatcher(ctx context.Context) *Watcher {
+	lastWatcher = &Watcher{ctx: ctx}
+	return lastWatcher
+}
+
+func (w *Watcher) Status() func(yield func(string) bool) {
+	return func(yield func(string) bool) {
+		status := "watching"
+		if err := w.ctx.Err(); err != nil {
+			status = err.Error()
+		}
+		yield(status)
+	}
+}
+
+func TestConstructor(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	testCases := []struct {
+		Name          string
+		Targets       interface{}
+		Args          []string
+		Expected      interface{}
+		ExpectedError error
+	}{
+		{"StructParameter", Constructor(NewClient), []string{"Fuego.Constructor.StructParameter", "Get", "/users", "--Host=db", "-p", "5432"}, "GET db:5432/users verbose=false", nil},
+		{"NamedParameters", Constructor(NewLocalClient), []string{"Fuego.Constructor.NamedParameters", "Get", "--port", "8080", "--secure", "/"}, "GET https://localhost:8080/ verbose=false", nil},
+		{"InstanceAttribute", Constructor(NewClient), []string{"Fuego.Constructor.InstanceAttribute", "Get", "/", "--Host", "db", "--Verbose"}, "GET db:0/ verbose=true", nil},
+		{"Slice", []interface{}{AddInt, Constructor(NewClient)}, []string{"Fuego.Constructor.Slice", "Client.Get", "/", "--Host=db"}, "GET db:0/ verbose=false", nil},
+		{"ConstructorError", Constructor(NewClient), []string{"Fuego.Constructor.ConstructorError", "Get", "/"}, nil, errors.Wrapf(errors.New("a host is required"), ConstructorFailedError, "NewClient")},
+		{"UnknownFlag", Constructor(NewClient), []string{"Fuego.Constructor.UnknownFlag", "Get", "/", "--Host=db", "--Bogus=1"}, nil, errors.Errorf(UnknownFlagError, "--Bogus", "Client")},
+		{"DuplicateParam", Constructor(NewMirroredClient), []string{"Fuego.Constructor.DuplicateParam", "Get", "/", "--Host=db"}, nil, errors.Errorf(DuplicateParamError, "NewMirroredClient", "ClientConfig")},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			os.Args = testCase.Args
+			values, err := Fuego(testCase.Targets)
+
+			if testCase.ExpectedError != nil {
+				if err == nil || !doErrorsMatch(testCase.ExpectedError, err) {
+					t.Errorf("Expected to receive the first error but instead the second error was returned: \n\t1) \"%v\"\n\t2) \"%v\"", testCase.ExpectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("Error is not expected but got %v", err)
+			} else if len(values) != 1 || !reflect.DeepEqual(values[0].Interface(), testCase.Expected) {
+				t.Errorf("expected %v but got %v", testCase.Expected, values)
+			}
+		})
+	}
+}
+
+func TestConstructorContext(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	os.Args = []string{"Fuego.Constructor.Context", "Status"}
+	values, err := Fuego(Constructor(NewWatcher))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the stream is read after the call returns, the context of the constructor must still be alive
+	var statuses []string
+	values[0].Interface().(func(yield func(string) bool))(func(status string) bool {
+		statuses = append(statuses, status)
+		return true
+	})
+	if !reflect.DeepEqual(statuses, []string{"watching"}) {
+		t.Errorf("expected the constructor context to be alive until the stream is drained but got %v", statuses)
+	}
+
+	if err := Release(values); err != nil {
+		t.Errorf("Error is not expected but got %v", err)
+	}
+	if lastWatcher.ctx.Err() == nil {
+		t.Error("expected the constructor context to be cancelled once the stream is released")
+	}
+}
+
+func TestConstructorInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a constructor that does not return a struct to panic")
+		}
+	}()
+	Constructor(AddInt)
+}
+
+func TestWriteHelpConstructor(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeHelp(&out, Constructor(NewLocalClient)); err != nil {
+		t.Fatal(err)
+	}
+
+	help := strings.Join(strings.Fields(out.String()), " ")
+	for _, expected := range []string{"Client.Get <path string>", "--port=<int> NewLocalClient parameter", "--secure=<bool> NewLocalClient parameter", "--Verbose=<bool> Client attribute"} {
+		if !strings.Contains(help, expected) {
+			t.Errorf("expected the help to contain %q but got:\n%v", expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,42 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+)
+
+// contextType is the reflect type of context.Context, used to detect functions and methods that expect a context as their first parameter
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// acceptsContext reports whether the first parameter of the function type is a context.Context that fuego should inject
+func acceptsContext(funcType reflect.Type) bool {
+	return funcType.NumIn() > 0 && funcType.In(0) == contextType
+}
+
+// newContext creates the context injected into targets, which is cancelled on SIGINT / SIGTERM and carries a deadline when --timeout is set.
+// The returned cancel function must be called once the target returns so the signal handlers are released.
+func (opts *options) newContext() (context.Context, context.CancelFunc) {
+	parent := opts.baseContext
+	if parent == nil {
+		parent = context.Background()
+	}
+
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	if opts.timeout <= 0 {
+		return ctx, stop
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
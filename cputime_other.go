@@ -0,0 +1,15 @@
+//go:build !unix
+
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import "time"
+
+// cpuTime is not measured on platforms without getrusage, so --time reports a cpu time of 0
+func cpuTime() time.Duration {
+	return 0
+}
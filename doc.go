@@ -0,0 +1,307 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	InvalidParamNamesError = "\"%v\" takes %v parameters but %v parameter names were declared"
+)
+
+// funcDoc describes a function or method as it was declared in the source, falling back to generated parameter names when the source is not available
+type funcDoc struct {
+	// Doc is the doc comment of the declaration
+	Doc string
+	// Params holds the name of every parameter, including a leading context.Context
+	Params []string
+	// Generated marks the parameter names that were generated (ie. arg0) as neither the source nor ParamNames declared them
+	Generated []bool
+}
+
+var (
+	// parsedFiles caches the parsed source files used to look up function declarations
+	parsedFiles      = map[string]*ast.File{}
+	parsedFilesMutex sync.Mutex
+
+	// declaredParams holds the parameter names declared with ParamNames, keyed by funcKey
+	declaredParams      = map[string][]string{}
+	declaredParamsMutex sync.Mutex
+)
+
+// ParamNames declares the parameter names of a function or struct method, ie. fuego.ParamNames(AddInt, "a", "b") or fuego.ParamNames(MyMath.Add, "a", "b").
+// Parameter names are otherwise read from the source file the function was compiled from, which is not available to deployed (or -trimpath) binaries
+// nor to method values such as MyMath{}.Add. The names are used by named JSON bodies, tool calls, the OpenAPI and tool schemas, --help and environment
+// variables, without them parameters can only be passed by position. A leading context.Context (and the receiver of a method expression) is not named.
+// ParamNames panics when the number of names does not match the parameters of fn.
+func ParamNames(fn interface{}, names ...string) {
+	fnVal := reflect.ValueOf(fn)
+	if fnVal.Kind() != reflect.Func {
+		panic(errors.Errorf(UnsupportedTargetTypeError, fnVal.Kind()))
+	}
+
+	fullName := runtime.FuncForPC(fnVal.Pointer()).Name()
+	receiver, name := splitFuncName(fullName)
+
+	// method expressions (ie. MyMath.Add) take the receiver as their first parameter unlike method values (ie. MyMath{}.Add)
+	fnType, start := fnVal.Type(), 0
+	if receiver != "" && !strings.HasSuffix(fullName, "-fm") {
+		start = 1
+	}
+	if fnType.NumIn() > start && fnType.In(start) == contextType {
+		start++
+	}
+	if expected := fnType.NumIn() - start; len(names) != expected {
+		panic(errors.Errorf(InvalidParamNamesError, name, expected, len(names)))
+	}
+
+	// the names are stored for every parameter of the method value, including the leading context.Context
+	if start > 0 && fnType.In(start-1) == contextType {
+		names = append([]string{"ctx"}, names...)
+	}
+
+	declaredParamsMutex.Lock()
+	defer declaredParamsMutex.Unlock()
+	declaredParams[funcKey(funcPackage(fullName), receiver, name)] = names
+}
+
+// describeFunc looks up the declaration of the function (or method value) in its source file to find its doc comment and parameter names
+func describeFunc(fn reflect.Value) funcDoc {
+	fullName := runtime.FuncForPC(fn.Pointer()).Name()
+	receiver, name := splitFuncName(fullName)
+	file, _ := runtime.FuncForPC(fn.Pointer()).FileLine(fn.Pointer())
+	return lookupFuncDoc(file, funcPackage(fullName), receiver, name, fn.Type().NumIn())
+}
+
+// describeMethod looks up the declaration of the struct method in its source file to find its doc comment and parameter names
+func describeMethod(structType reflect.Type, methodName string) funcDoc {
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+
+	// value receiver methods are found on the struct type, otherwise the method has a pointer receiver
+	method, ok := structType.MethodByName(methodName)
+	if !ok {
+		method, _ = reflect.PtrTo(structType).MethodByName(methodName)
+	}
+
+	// the method func has the receiver as its first parameter which is not part of the method value
+	file, _ := runtime.FuncForPC(method.Func.Pointer()).FileLine(method.Func.Pointer())
+	return lookupFuncDoc(file, structType.PkgPath(), structType.Name(), methodName, method.Type.NumIn()-1)
+}
+
+// splitFuncName splits a runtime function name (ie. "github.com/irasekh3/fuego.(*MyMath).Add-fm") into the receiver and function names
+func splitFuncName(fullName string) (string, string) {
+	fullName = strings.TrimSuffix(fullName, "-fm")
+	parts := strings.Split(fullName[strings.LastIndex(fullName, "/")+1:], ".")
+
+	name := parts[len(parts)-1]
+	if len(parts) < 3 {
+		return "", name
+	}
+	return strings.Trim(parts[len(parts)-2], "(*)"), name
+}
+
+// funcPackage returns the package path of a runtime function name, ie. "github.com/irasekh3/fuego" for "github.com/irasekh3/fuego.(*MyMath).Add-fm"
+func funcPackage(fullName string) string {
+	slash := strings.LastIndex(fullName, "/") + 1
+	if dot := strings.Index(fullName[slash:], "."); dot >= 0 {
+		return fullName[:slash+dot]
+	}
+	return fullName
+}
+
+// funcKey identifies a function or method by its package, receiver and name, ie. "github.com/irasekh3/fuego.MyMath.Add"
+func funcKey(pkg string, receiver string, name string) string {
+	if receiver == "" {
+		return pkg + "." + name
+	}
+	return pkg + "." + receiver + "." + name
+}
+
+// lookupFuncDoc finds the declaration in the source file, the parameter names declared with ParamNames take precedence over the source and
+// generated parameter names (ie. arg0) are used when neither is available
+func lookupFuncDoc(file string, pkg string, receiver string, name string, paramCount int) funcDoc {
+	doc := funcDoc{Params: make([]string, paramCount), Generated: make([]bool, paramCount)}
+	for x := range doc.Params {
+		doc.Params[x] = "arg" + strconv.Itoa(x)
+		doc.Generated[x] = true
+	}
+
+	if decl := findFuncDecl(file, receiver, name); decl != nil {
+		doc.Doc = strings.TrimSpace(decl.Doc.Text())
+		doc.readParams(decl)
+	}
+
+	declaredParamsMutex.Lock()
+	names, ok := declaredParams[funcKey(pkg, receiver, name)]
+	declaredParamsMutex.Unlock()
+	if ok && len(names) == paramCount {
+		copy(doc.Params, names)
+		for x := range doc.Generated {
+			doc.Generated[x] = false
+		}
+	}
+	return doc
+}
+
+// readParams replaces the generated parameter names with the names of the declaration, unnamed (or _) parameters keep their generated names
+func (doc *funcDoc) readParams(decl *ast.FuncDecl) {
+
+	var names []string
+	for _, field := range decl.Type.Params.List {
+		if len(field.Names) == 0 {
+			names = append(names, "")
+		}
+		for _, fieldName := range field.Names {
+			names = append(names, fieldName.Name)
+		}
+	}
+
+	if len(names) == len(doc.Params) {
+		for x, paramName := range names {
+			if paramName != "" && paramName != "_" {
+				doc.Params[x] = paramName
+				doc.Generated[x] = false
+			}
+		}
+	}
+}
+
+// findFuncDecl parses (and caches) the source file to find the declaration of the function with the receiver and name
+func findFuncDecl(file string, receiver string, name string) *ast.FuncDecl {
+	if !strings.HasSuffix(file, ".go") {
+		return nil
+	}
+
+	parsedFilesMutex.Lock()
+	parsed, ok := parsedFiles[file]
+	if !ok {
+		parsed, _ = parser.ParseFile(token.NewFileSet(), file, nil, parser.ParseComments)
+		parsedFiles[file] = parsed
+	}
+	parsedFilesMutex.Unlock()
+
+	if parsed == nil {
+		return nil
+	}
+
+	for _, decl := range parsed.Decls {
+		funcDecl, ok := decl.(*ast.FuncDecl)
+		if !ok || funcDecl.Name.Name != name {
+			continue
+		}
+
+		declReceiver := ""
+		if funcDecl.Recv != nil && len(funcDecl.Recv.List) > 0 {
+			recvType := funcDecl.Recv.List[0].Type
+			if star, ok := recvType.(*ast.StarExpr); ok {
+				recvType = star.X
+			}
+			if ident, ok := recvType.(*ast.Ident); ok {
+				declReceiver = ident.Name
+			}
+		}
+
+		if declReceiver == receiver {
+			return funcDecl
+		}
+	}
+	return nil
+}
+
+// command describes a function or struct method within the targets that can be called
+type command struct {
+	// Name is the name used to call the command, ie. "AddInt" or "MyMath.Add"
+	Name string
+	// Struct is the struct type the method belongs to, nil for functions
+	Struct reflect.Type
+	// Func is the type of the function or method value
+	Func reflect.Type
+	// Doc describes the declaration of the function or method
+	Doc funcDoc
+}
+
+// listCommands lists every function and struct method within the targets that can be called
+func listCommands(targets interface{}) []command {
+	var commands []command
+
+	switch t := targets.(type) {
+	case []interface{}:
+		for _, target := range t {
+			commands = append(commands, listCommands(target)...)
+		}
+	case *constructor:
+		// the commands of a constructor are the methods of the struct it builds
+		commands = listCommands(reflect.New(t.structType).Interface())
+	default:
+		targetType := reflect.TypeOf(targets)
+		switch {
+		case targetType.Kind() == reflect.Func:
+			targetVal := reflect.ValueOf(targets)
+			commands = append(commands, command{Name: functionName(targets), Func: targetType, Doc: describeFunc(targetVal)})
+		case targetType.Kind() == reflect.Struct || (targetType.Kind() == reflect.Ptr && targetType.Elem().Kind() == reflect.Struct):
+			structType := targetType
+			if structType.Kind() == reflect.Ptr {
+				structType = structType.Elem()
+			}
+
+			// the pointer method set holds both the value and pointer receiver methods
+			ptrType := reflect.PtrTo(structType)
+			for x := 0; x < ptrType.NumMethod(); x++ {
+				methodName := ptrType.Method(x).Name
+				commands = append(commands, command{
+					Name:   structType.Name() + "." + methodName,
+					Struct: structType,
+					Func:   reflect.Zero(ptrType).MethodByName(methodName).Type(),
+					Doc:    describeMethod(structType, methodName),
+				})
+			}
+		}
+	}
+
+	return commands
+}
+
+// Params returns the names and types of the parameters that are parsed from the args, skipping a leading context.Context that fuego injects
+func (c command) Params() ([]string, []reflect.Type) {
+	offset := 0
+	if acceptsContext(c.Func) {
+		offset = 1
+	}
+
+	types := make([]reflect.Type, 0, c.Func.NumIn())
+	for x := offset; x < c.Func.NumIn(); x++ {
+		types = append(types, c.Func.In(x))
+	}
+	return c.Doc.Params[offset:], types
+}
+
+// HasParamNames reports whether the real names of the parameters parsed from the args are known, either from the source or ParamNames,
+// rather than generated (ie. arg0) so that they can be passed by name
+func (c command) HasParamNames() bool {
+	offset := 0
+	if acceptsContext(c.Func) {
+		offset = 1
+	}
+
+	for _, generated := range c.Doc.Generated[offset:] {
+		if generated {
+			return false
+		}
+	}
+	return true
+}
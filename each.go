@@ -0,0 +1,209 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	EachRecordError          = "line \"%v\" of the --each input failed"
+	EachFailedError          = "\"%v\" of the \"%v\" --each records failed"
+	EachCommandNotFoundError = "the command for the named JSON record could not be found, pass the command name before the parameters"
+	EachStdinError           = "std in is read for the --each records so it can not be read by a record"
+)
+
+// eachRecord is a single line of the --each input, numbered by its line so errors can be traced back to the input
+type eachRecord struct {
+	index int
+	line  int
+	text  string
+}
+
+// eachResult is the rendered output (or the error) of calling the target with a single record
+type eachResult struct {
+	index  int
+	output []byte
+	err    error
+}
+
+// runEach calls the target once for every line read from the input, appending the fields of the line (or the parameters of a JSON record) to the args.
+// Lines are split like a shell command line, JSON arrays are positional parameters and JSON objects are named parameters and struct attributes as with --serve.
+// Up to --jobs records are called at once, each against its own copy of the targets, and the results are written in input order unless --unordered is set.
+// Errors are reported with their line number and stop reading the input when --stop-on-error is set.
+func runEach(targets interface{}, args []string, opts *options, in io.Reader, out io.Writer) error {
+	cmd, hasCmd := eachCommand(targets, args)
+	// the records are read from the input so "-" args and io.Reader params of the records can not read it as well
+	recordOpts := *opts
+	recordOpts.stdin = eachStdin{}
+
+	records := make(chan eachRecord)
+	results := make(chan eachResult)
+	stop := make(chan struct{})
+
+	var readErr error
+	go func() {
+		defer close(records)
+
+		scanner := bufio.NewScanner(in)
+		for line, index := 1, 0; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+
+			select {
+			case records <- eachRecord{index: index, line: line, text: text}:
+				index++
+			case <-stop:
+				return
+			}
+		}
+		readErr = scanner.Err()
+	}()
+
+	var workers sync.WaitGroup
+	for x := 0; x < opts.jobCount(); x++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for record := range records {
+				results <- runEachRecord(targets, cmd, hasCmd, args, &recordOpts, record)
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	recordCount, failedCount := 0, 0
+	var firstErr error
+	// emit writes the result, reporting its error. Once stopped the results still being called are drained without being written
+	emit := func(result eachResult) {
+		if firstErr != nil && opts.stopOnError {
+			return
+		}
+
+		recordCount++
+		_, _ = out.Write(result.output)
+		if result.err == nil {
+			return
+		}
+
+		failedCount++
+		if firstErr == nil {
+			firstErr = result.err
+		}
+		printError(result.err)
+		if opts.stopOnError {
+			close(stop)
+		}
+	}
+
+	// in order results are held back until every earlier record has been written
+	pending := map[int]eachResult{}
+	next := 0
+	for result := range results {
+		if opts.unordered {
+			emit(result)
+			continue
+		}
+
+		pending[result.index] = result
+		for result, ok := pending[next]; ok; result, ok = pending[next] {
+			delete(pending, next)
+			emit(result)
+			next++
+		}
+	}
+
+	if readErr != nil {
+		return readErr
+	} else if failedCount == 1 || (failedCount > 0 && opts.stopOnError) {
+		return firstErr
+	} else if failedCount > 1 {
+		return errors.Errorf(EachFailedError, failedCount, recordCount)
+	}
+	return nil
+}
+
+// runEachRecord calls the target with the args of the record, rendering the returned values like a --script line
+func runEachRecord(targets interface{}, cmd command, hasCmd bool, args []string, opts *options, record eachRecord) eachResult {
+	result := eachResult{index: record.index}
+
+	recordArgs, err := eachRecordArgs(cmd, hasCmd, record.text)
+	if err == nil {
+		var buf bytes.Buffer
+		// every record is called against its own copy of the targets so records called at once do not share struct attributes
+		values, callErr := dispatch(cloneTargets(targets), append(append([]string{}, args...), recordArgs...), opts)
+		if err = callErr; err == nil {
+			err = printValues(&buf, values, opts)
+		}
+		// the streams are not drained when they are not printed
+		if releaseErr := Release(values); releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+		if err == nil && PrintToStdOut && opts.format == nil && !hasStreamResults(values) {
+			fmt.Fprintln(&buf)
+		}
+		result.output = buf.Bytes()
+	}
+
+	if err != nil {
+		result.err = errors.Wrapf(err, EachRecordError, record.line)
+	}
+	return result
+}
+
+// eachStdin takes the place of std in for the --each records, every read fails as std in holds the records themselves
+type eachStdin struct{}
+
+func (eachStdin) Read([]byte) (int, error) {
+	return 0, errors.New(EachStdinError)
+}
+
+// eachRecordArgs converts the record into args, JSON arrays and objects are converted like the body of a --serve request while other lines are split like a shell command line
+func eachRecordArgs(cmd command, hasCmd bool, text string) ([]string, error) {
+	if (text[0] != '[' && text[0] != '{') || !json.Valid([]byte(text)) {
+		return splitCommandLine(text)
+	}
+
+	if text[0] == '{' && !hasCmd {
+		return nil, errors.New(EachCommandNotFoundError)
+	}
+	return commandArgs(cmd, []byte(text))
+}
+
+// eachCommand finds the command called with --each so named JSON records can be mapped to its parameters, a single function target may be called without its name
+func eachCommand(targets interface{}, args []string) (command, bool) {
+	commands := listCommands(targets)
+	if len(args) > 1 {
+		if cmd, ok := findCommand(targets, commands, args[1]); ok {
+			return cmd, true
+		}
+	}
+	if len(commands) == 1 && commands[0].Struct == nil {
+		return commands[0], true
+	}
+	return command{}, false
+}
+
+// jobCount returns the number of records --each calls at once, one unless --jobs is set
+func (opts *options) jobCount() int {
+	if opts.jobs > 0 {
+		return opts.jobs
+	}
+	return 1
+}
@@ -0,0 +1,93 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestRunEach(t *testing.T) {
+	PrintToStdErr = false
+
+	testCases := []struct {
+		Name           string
+		Targets        interface{}
+		Args           []string
+		Input          string
+		ExpectedOutput string
+		ExpectedError  error
+	}{
+		{"Lines", []interface{}{AddInt, MyMath{}}, []string{"fuego", "AddInt", "10"}, "1\n\n2\n3\n", "11\n12\n13\n", nil},
+		{"QuotedFields", AddInt, []string{"fuego"}, "1 2\n'3' \"4\"\n", "3\n7\n", nil},
+		{"JSONRecords", AddInt, []string{"fuego"}, "{\"a\": 1, \"b\": 2}\n[3, 4]\n", "3\n7\n", nil},
+		{"StructFlags", &MyMath{}, []string{"fuego", "Add", "--Offset=1"}, "1 2\n{\"a\": 3, \"b\": 4, \"Offset\": 10}\n", "4\n17\n", nil},
+		{"Format", AddInt, []string{"fuego", "--format={{.}}!"}, "1 2\n3 4\n", "3!\n7!\n", nil},
+		{"Jobs", AddInt, []string{"fuego", "--jobs=4", "0"}, "1\n2\n3\n4\n5\n6\n7\n8\n", "1\n2\n3\n4\n5\n6\n7\n8\n", nil},
+		{"RecordError", AddInt, []string{"fuego"}, "1 2\n1 x\n3 4\n", "3\n7\n", errors.Errorf(EachRecordError+": %v: %v", 2, ParameterListGenerationError, CannotConvertToDesiredValueTypeError)},
+		{"StopOnError", AddInt, []string{"fuego", "--stop-on-error"}, "1 2\n1 x\n3 4\n", "3\n", errors.Errorf(EachRecordError+": %v: %v", 2, ParameterListGenerationError, CannotConvertToDesiredValueTypeError)},
+		{"Failed", AddInt, []string{"fuego"}, "1 x\n1 2\n1 y\n", "3\n", errors.Errorf(EachFailedError, 2, 3)},
+		{"StdinField", AddInt, []string{"fuego"}, "1 -\n", "", errors.Errorf(EachRecordError+": %v: %v", 1, StdinReadError, EachStdinError)},
+		{"NamedRecordWithoutCommand", []interface{}{AddInt, SubtractInt}, []string{"fuego"}, "{\"a\": 1}\n", "", errors.Errorf(EachRecordError+": %v", 1, EachCommandNotFoundError)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			PrintToStdOut = true
+			defer func() { PrintToStdOut = false }()
+
+			var out bytes.Buffer
+			opts := &options{localArgs: true}
+			args, err := opts.parse(testCase.Args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = runEach(testCase.Targets, args, opts, strings.NewReader(testCase.Input), &out)
+
+			if testCase.ExpectedError != nil {
+				if err == nil || !doErrorsMatch(testCase.ExpectedError, err) {
+					t.Errorf("Expected to receive the first error but instead the second error was returned: \n\t1) \"%v\"\n\t2) \"%v\"", testCase.ExpectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("Error is not expected but got %v", err)
+			}
+			if out.String() != testCase.ExpectedOutput {
+				t.Errorf("expected the output %q but got %q", testCase.ExpectedOutput, out.String())
+			}
+		})
+	}
+}
+
+func TestRunEachUnordered(t *testing.T) {
+	PrintToStdOut = true
+	PrintToStdErr = false
+	defer func() { PrintToStdOut = false }()
+
+	var input, expected []string
+	for x := 0; x < 50; x++ {
+		input = append(input, fmt.Sprint(x))
+		expected = append(expected, fmt.Sprint(x+1))
+	}
+
+	var out bytes.Buffer
+	opts := &options{jobs: 8, unordered: true}
+	if err := runEach(AddInt, []string{"fuego", "1"}, opts, strings.NewReader(strings.Join(input, "\n")), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	// the results are written as they complete so only the set of results is compared
+	results := strings.Fields(out.String())
+	sort.Strings(results)
+	sort.Strings(expected)
+	if strings.Join(results, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected the results %v but got %v", expected, results)
+	}
+}
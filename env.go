@@ -0,0 +1,131 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	EnvAttributeError = "the environment variable \"%v\" could not set the attribute \"%v\" of \"%v\""
+)
+
+// applyEnv sets the struct attributes within the targets from their environment variables, ie. MYMATH_OFFSET, DEPLOYMENT_DB_HOST for the nested --DB.Host
+// or the `fuego:"env=OFFSET"` tag. Struct values are copied into pointers when a variable is set so their attributes can be set, values that can not be converted are reported and skipped.
+func applyEnv(targets interface{}) interface{} {
+	if !hasEnvAttributes(targets) {
+		return targets
+	}
+
+	targets = addressableTargets(targets)
+	for _, target := range targetList(targets) {
+		structVal := reflect.ValueOf(target)
+		if structVal.Kind() != reflect.Ptr || structVal.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		structVal = structVal.Elem()
+
+		walkAttributes(structVal.Type(), func(name string, field reflect.StructField, nested bool) {
+			if isElementPath(name) {
+				return
+			}
+
+			env := attributeEnvName(structVal.Type(), name, field)
+			value, ok := os.LookupEnv(env)
+			if !ok {
+				return
+			}
+
+			if _, err := setAttribute(structVal, name, false, value); err != nil {
+				printError(errors.Wrapf(err, EnvAttributeError, env, name, structVal.Type().Name()))
+			}
+		})
+	}
+	return targets
+}
+
+// hasEnvAttributes reports whether any struct attribute within the targets has its environment variable set
+func hasEnvAttributes(targets interface{}) bool {
+	hasEnv := false
+	for _, target := range targetList(targets) {
+		structType := reflect.TypeOf(target)
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+		if structType.Kind() != reflect.Struct {
+			continue
+		}
+
+		walkAttributes(structType, func(name string, field reflect.StructField, nested bool) {
+			if _, ok := os.LookupEnv(attributeEnvName(structType, name, field)); ok && !isElementPath(name) {
+				hasEnv = true
+			}
+		})
+	}
+	return hasEnv
+}
+
+// attributeEnvName returns the environment variable the struct attribute at the path is bound to, the `fuego:"env=<NAME>"` tag or <STRUCT>_<ATTRIBUTE>,
+// ie. MYMATH_OFFSET, with every part of a nested path separated by an underscore, ie. DEPLOYMENT_DB_HOST for DB.Host
+func attributeEnvName(structType reflect.Type, name string, field reflect.StructField) string {
+	if tag := parseFuegoTag(field); tag.env != "" {
+		return tag.env
+	}
+
+	parts := strings.Split(name, ".")
+	for x, part := range parts {
+		parts[x] = envName(part)
+	}
+	return envPrefix(structType.Name()) + "_" + strings.Join(parts, "_")
+}
+
+// envParams returns the values of the environment variables bound to the named params (ie. ADDINT_B), stopping at the first variable that is not set
+func envParams(prefix string, paramNames []string) []string {
+	var values []string
+	for _, paramName := range paramNames {
+		value, ok := os.LookupEnv(prefix + "_" + envName(paramName))
+		if !ok {
+			break
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// envParamNames returns the names of the params from start that can be bound to environment variables, stopping at the first generated name (ie. arg1)
+// as the variable name would change with the build rather than follow the declaration
+func envParamNames(doc funcDoc, start int) []string {
+	end := start
+	for end < len(doc.Params) && !doc.Generated[end] {
+		end++
+	}
+	return doc.Params[start:end]
+}
+
+// envPrefix returns the environment variable prefix for a struct or function, its upper cased name (ie. MYMATH for MyMath)
+func envPrefix(name string) string {
+	return strings.ToUpper(name)
+}
+
+// envName converts an attribute or parameter name into its environment variable form, ie. MAX_RETRIES for MaxRetries and HTTP_PORT for HTTPPort
+func envName(name string) string {
+	runes := []rune(name)
+
+	var env strings.Builder
+	for x, r := range runes {
+		// a new word starts at an upper case letter following a lower case letter or digit, or ending an acronym (ie. the P in HTTPPort)
+		if x > 0 && unicode.IsUpper(r) && (!unicode.IsUpper(runes[x-1]) || (x+1 < len(runes) && unicode.IsLower(runes[x+1]))) {
+			env.WriteRune('_')
+		}
+		env.WriteRune(unicode.ToUpper(r))
+	}
+	return env.String()
+}
@@ -0,0 +1,129 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type EnvServer struct {
+	Host       string
+	HTTPPort   int
+	MaxRetries int `fuego:"env=RETRIES"`
+	token      string
+}
+
+func (s EnvServer) Address(scheme string, path string) string {
+	return fmt.Sprintf("%v://%v:%v%v", scheme, s.Host, s.HTTPPort, path)
+}
+
+func (s EnvServer) Retries() int {
+	return s.MaxRetries
+}
+
+func TestEnv(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"Host": "config", "HTTPPort": 1}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name          string
+		Env           map[string]string
+		Targets       interface{}
+		Args          []string
+		Expected      interface{}
+		ExpectedError error
+	}{
+		{"Attribute", map[string]string{"ENVSERVER_HOST": "env", "ENVSERVER_HTTP_PORT": "8"}, EnvServer{}, []string{"Fuego.Env.Attribute", "Address", "http", "/"}, "http://env:8/", nil},
+		{"EnvOverridesConfig", map[string]string{"ENVSERVER_HOST": "env"}, &EnvServer{}, []string{"Fuego.Env.EnvOverridesConfig", "Address", "http", "/", "--config=" + configPath}, "http://env:1/", nil},
+		{"FlagOverridesEnv", map[string]string{"ENVSERVER_HOST": "env"}, &EnvServer{}, []string{"Fuego.Env.FlagOverridesEnv", "Address", "http", "/", "--Host=flag"}, "http://flag:0/", nil},
+		{"InvalidValueSkipped", map[string]string{"ENVSERVER_HOST": "env", "ENVSERVER_HTTP_PORT": "x"}, &EnvServer{HTTPPort: 2}, []string{"Fuego.Env.InvalidValueSkipped", "Address", "http", "/"}, "http://env:2/", nil},
+		{"StructParameter", map[string]string{"ENVSERVER_PATH": "/env"}, []interface{}{EnvServer{Host: "h"}}, []string{"Fuego.Env.StructParameter", "EnvServer.Address", "https"}, "https://h:0/env", nil},
+		{"TaggedAttribute", map[string]string{"RETRIES": "3", "ENVSERVER_MAX_RETRIES": "4"}, EnvServer{}, []string{"Fuego.Env.TaggedAttribute", "Retries"}, 3, nil},
+		{"NamedType", map[string]string{"TAGGEDSERVER_MODE": "slow"}, &TaggedServer{}, []string{"Fuego.Env.NamedType", "Describe"}, "mode=slow db=localhost:5432", nil},
+		{"NestedAttribute", map[string]string{"TAGGEDSERVER_DB_HOST": "envdb", "TAGGEDSERVER_DB_PORT": "1"}, TaggedServer{}, []string{"Fuego.Env.NestedAttribute", "Describe"}, "mode=fast db=envdb:1", nil},
+		{"EmbeddedAttribute", map[string]string{"DEPLOYMENT_REGION": "eu", "DEPLOYMENT_PRIMARY_HOST": "p"}, &Deployment{}, []string{"Fuego.Env.EmbeddedAttribute", "Describe"}, "region=eu db= primary=p servers=[] replicas=[] labels=map[] ports=[]", nil},
+		{"FunctionParameter", map[string]string{"ADDINT_B": "5"}, AddInt, []string{"Fuego.Env.FunctionParameter", "AddInt", "1"}, 6, nil},
+		{"FunctionParameters", map[string]string{"ADDINT_A": "2", "ADDINT_B": "5"}, AddInt, []string{"Fuego.Env.FunctionParameters"}, 7, nil},
+		{"FunctionParameterMissing", map[string]string{"ADDINT_A": "2"}, AddInt, []string{"Fuego.Env.FunctionParameterMissing"}, nil, errors.New(InsufficientArgumentsError)},
+		{"GeneratedParameterNamesNotBound", map[string]string{"ADDRESS_ARG0": "http", "ADDRESS_ARG1": "/"}, EnvServer{}.Address, []string{"Fuego.Env.GeneratedParameterNamesNotBound"}, nil, errors.New(InsufficientArgumentsError)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			for key, value := range testCase.Env {
+				t.Setenv(key, value)
+			}
+
+			os.Args = testCase.Args
+			values, err := Fuego(testCase.Targets)
+
+			if testCase.ExpectedError != nil {
+				if err == nil || !doErrorsMatch(testCase.ExpectedError, err) {
+					t.Errorf("Expected to receive the first error but instead the second error was returned: \n\t1) \"%v\"\n\t2) \"%v\"", testCase.ExpectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("Error is not expected but got %v", err)
+			} else if len(values) != 1 || !reflect.DeepEqual(values[0].Interface(), testCase.Expected) {
+				t.Errorf("expected %v but got %v", testCase.Expected, values)
+			}
+		})
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	for name, expected := range map[string]string{"Offset": "OFFSET", "MaxRetries": "MAX_RETRIES", "HTTPPort": "HTTP_PORT", "userID": "USER_ID", "v2Host": "V2_HOST", "a": "A"} {
+		if got := envName(name); got != expected {
+			t.Errorf("expected the environment variable name %q for %q but got %q", expected, name, got)
+		}
+	}
+}
+
+func TestWriteHelp(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeHelp(&out, []interface{}{Greet, EnvServer{}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// the columns are aligned with spaces which are collapsed to compare the contents
+	help := strings.Join(strings.Fields(out.String()), " ")
+	for _, expected := range []string{
+		"Greet <name string> <times int> Greet says hello to the name, repeating the greeting the number of times. [env GREET_NAME, GREET_TIMES]",
+		"EnvServer.Address <scheme string> <path string> [env ENVSERVER_SCHEME, ENVSERVER_PATH]",
+		"--HTTPPort=<int>", "EnvServer attribute [env ENVSERVER_HTTP_PORT]",
+		"EnvServer attribute [env RETRIES]",
+		"--format=<template>",
+	} {
+		if !strings.Contains(help, expected) {
+			t.Errorf("expected the help to contain %q but got:\n%v", expected, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "token") {
+		t.Errorf("expected the unexported attribute to be left out of the help but got:\n%v", out.String())
+	}
+
+	// the parameter names of method values are generated so they are not bound to environment variables
+	out.Reset()
+	if err := writeHelp(&out, EnvServer{}.Address); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "ADDRESS_ARG0") {
+		t.Errorf("expected the generated parameter names to be left out of the environment variables but got:\n%v", out.String())
+	}
+}
@@ -0,0 +1,342 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	SliceIndexTooLargeError = "the index %v of \"%v\" is too large, slices are only grown by up to %v elements"
+)
+
+// maxSliceGrowth bounds how far past its length a slice is grown to reach an index, so an index like --Servers[99999999999999].Host
+// returns an error rather than exhausting memory. Indexes may still skip ahead (ie. a config sets Servers[10] before Servers[2])
+const maxSliceGrowth = 1000
+
+// pathPart is a single step of an attribute path, either a field name or an index, ie. Servers[0].Host is "Servers", [0] and "Host"
+type pathPart struct {
+	name  string
+	index bool
+}
+
+// splitAttributePath splits the attribute name into the field names and indexes of its path, reporting whether the path is well formed
+func splitAttributePath(name string) ([]pathPart, bool) {
+	var parts []pathPart
+	for name != "" {
+		if name[0] == '[' {
+			end := strings.IndexByte(name, ']')
+			if end < 0 {
+				return nil, false
+			}
+			parts = append(parts, pathPart{name: name[1:end], index: true})
+			name = strings.TrimPrefix(name[end+1:], ".")
+			continue
+		}
+
+		end := strings.IndexAny(name, ".[")
+		if end < 0 {
+			end = len(name)
+		} else if end == 0 {
+			return nil, false
+		}
+		parts = append(parts, pathPart{name: name[:end]})
+		name = strings.TrimPrefix(name[end:], ".")
+	}
+	return parts, len(parts) > 0
+}
+
+// findFieldIndex finds the exported field of the struct by its flag name or go field name, optionally without regard to case (ie. for config files).
+// The fields of embedded structs are promoted, so the index may lead through the embedded struct as with reflect.Type.FieldByIndex
+func findFieldIndex(structType reflect.Type, name string, foldCase bool) ([]int, bool) {
+	matches := func(candidate string) bool {
+		return candidate == name || (foldCase && strings.EqualFold(candidate, name))
+	}
+
+	for x := 0; x < structType.NumField(); x++ {
+		if field := structType.Field(x); field.PkgPath == "" && (matches(attributeName(field)) || matches(field.Name)) {
+			return []int{x}, true
+		}
+	}
+
+	for x := 0; x < structType.NumField(); x++ {
+		field := structType.Field(x)
+		if !field.Anonymous || (field.Type.Kind() == reflect.Ptr && field.PkgPath != "") {
+			continue
+		}
+
+		embedded := field.Type
+		if embedded.Kind() == reflect.Ptr {
+			embedded = embedded.Elem()
+		}
+		if embedded.Kind() != reflect.Struct {
+			continue
+		}
+		if index, ok := findFieldIndex(embedded, name, foldCase); ok {
+			return append([]int{x}, index...), true
+		}
+	}
+	return nil, false
+}
+
+// shortAttributeName returns the name of the struct attribute with the `fuego:"short=<letter>"` alias, or "" when there is none.
+// The attributes of embedded structs are promoted along with their aliases
+func shortAttributeName(structType reflect.Type, short string) string {
+	for x := 0; x < structType.NumField(); x++ {
+		field := structType.Field(x)
+		if field.PkgPath == "" && short != "" && parseFuegoTag(field).short == short {
+			return field.Name
+		}
+		if embedded := indirectType(field.Type); field.Anonymous && embedded.Kind() == reflect.Struct {
+			if name := shortAttributeName(embedded, short); name != "" {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// attributeType returns the type of the struct attribute at the path, ie. DB.Host, Servers[0].Host or Labels[env], reporting whether the struct has the attribute
+func attributeType(structType reflect.Type, name string, foldCase bool) (reflect.Type, bool) {
+	parts, ok := splitAttributePath(name)
+	if !ok {
+		return nil, false
+	}
+
+	t := structType
+	for _, part := range parts {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+
+		switch {
+		case !part.index && t.Kind() == reflect.Struct:
+			index, ok := findFieldIndex(t, part.name, foldCase)
+			if !ok {
+				return nil, false
+			}
+			t = t.FieldByIndex(index).Type
+		case part.index && t.Kind() == reflect.Map:
+			t = t.Elem()
+		case part.index && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array):
+			x, err := strconv.Atoi(part.name)
+			if err != nil || x < 0 || (t.Kind() == reflect.Array && x >= t.Len()) {
+				return nil, false
+			}
+			t = t.Elem()
+		default:
+			return nil, false
+		}
+	}
+	return t, true
+}
+
+// attributeValue returns the current value of the struct attribute at the path without allocating anything along it,
+// the value is invalid when the path leads through a nil pointer or into a slice or map element
+func attributeValue(structVal reflect.Value, name string) reflect.Value {
+	parts, ok := splitAttributePath(name)
+	if !ok {
+		return reflect.Value{}
+	}
+
+	val := structVal
+	for _, part := range parts {
+		for val.Kind() == reflect.Ptr {
+			if val.IsNil() {
+				return reflect.Value{}
+			}
+			val = val.Elem()
+		}
+		if part.index || val.Kind() != reflect.Struct {
+			return reflect.Value{}
+		}
+
+		index, ok := findFieldIndex(val.Type(), part.name, false)
+		if !ok {
+			return reflect.Value{}
+		}
+		field, err := val.FieldByIndexErr(index)
+		if err != nil {
+			return reflect.Value{}
+		}
+		val = field
+	}
+	return val
+}
+
+// isZeroAttribute reports whether the struct attribute at the path is still unset, attributes behind nil pointers are unset
+func isZeroAttribute(structVal reflect.Value, name string) bool {
+	val := attributeValue(structVal, name)
+	return !val.IsValid() || val.IsZero()
+}
+
+// isElementPath reports whether the attribute name from walkAttributes describes the elements of a slice or map (ie. Servers[<index>].Host) rather than a single attribute
+func isElementPath(name string) bool {
+	return strings.Contains(name, "[<")
+}
+
+// setAttribute converts the value and sets the struct attribute at the path, reporting whether the struct has the attribute.
+// Nil pointers along the path are allocated, slices are grown to the index (see maxSliceGrowth) and maps are created, while attributes of a struct that is not addressable are skipped
+func setAttribute(structVal reflect.Value, name string, foldCase bool, value string) (bool, error) {
+	if _, ok := attributeType(structVal.Type(), name, foldCase); !ok {
+		return false, nil
+	}
+
+	parts, _ := splitAttributePath(name)
+	return true, setAttributePath(structVal, parts, foldCase, value)
+}
+
+// setAttributePath walks the path from the value, setting the attribute at the end of it
+func setAttributePath(val reflect.Value, parts []pathPart, foldCase bool, value string) error {
+	if len(parts) == 0 {
+		if !val.CanSet() {
+			return nil
+		}
+
+		vals, err := convertStringsToReflectValues([]reflect.Kind{val.Kind()}, []string{value})
+		if err != nil {
+			return err
+		}
+		val.Set(vals[0].Convert(val.Type()))
+		return nil
+	}
+
+	if val.Kind() == reflect.Ptr {
+		if !allocatePointer(val) {
+			return nil
+		}
+		return setAttributePath(val.Elem(), parts, foldCase, value)
+	}
+
+	part := parts[0]
+	switch val.Kind() {
+	case reflect.Struct:
+		index, _ := findFieldIndex(val.Type(), part.name, foldCase)
+		for n, x := range index {
+			if n > 0 && val.Kind() == reflect.Ptr {
+				if !allocatePointer(val) {
+					return nil
+				}
+				val = val.Elem()
+			}
+			val = val.Field(x)
+		}
+		return setAttributePath(val, parts[1:], foldCase, value)
+	case reflect.Map:
+		if !val.CanSet() {
+			return nil
+		}
+
+		keys, err := convertStringsToReflectValues([]reflect.Kind{val.Type().Key().Kind()}, []string{part.name})
+		if err != nil {
+			return err
+		}
+		key := keys[0].Convert(val.Type().Key())
+
+		// map elements are not addressable so the element is set on a copy which is then stored back in the map
+		elem := reflect.New(val.Type().Elem()).Elem()
+		if existing := val.MapIndex(key); existing.IsValid() {
+			elem.Set(existing)
+		}
+		if err := setAttributePath(elem, parts[1:], foldCase, value); err != nil {
+			return err
+		}
+
+		if val.IsNil() {
+			val.Set(reflect.MakeMap(val.Type()))
+		}
+		val.SetMapIndex(key, elem)
+		return nil
+	default:
+		x, _ := strconv.Atoi(part.name)
+		if val.Kind() == reflect.Slice && x >= val.Len() {
+			if !val.CanSet() {
+				return nil
+			}
+			if x-val.Len() >= maxSliceGrowth {
+				return errors.Errorf(SliceIndexTooLargeError, x, val.Type(), maxSliceGrowth)
+			}
+			val.Set(reflect.AppendSlice(val, reflect.MakeSlice(val.Type(), x+1-val.Len(), x+1-val.Len())))
+		}
+		return setAttributePath(val.Index(x), parts[1:], foldCase, value)
+	}
+}
+
+// allocatePointer points a nil pointer at a new zero value so the path can be followed through it, reporting whether the pointer can be followed
+func allocatePointer(val reflect.Value) bool {
+	if val.IsNil() {
+		if !val.CanSet() {
+			return false
+		}
+		val.Set(reflect.New(val.Type().Elem()))
+	}
+	return true
+}
+
+// walkAttributes calls fn with the flag name of every attribute of the struct, descending into nested and embedded structs, ie. DB.Host.
+// Slices and maps of structs are described with an <index> or <key> placeholder, ie. Servers[<index>].Host, and nested is true below the top level
+func walkAttributes(structType reflect.Type, fn func(name string, field reflect.StructField, nested bool)) {
+	walkAttributesFrom(structType, "", false, map[reflect.Type]bool{}, fn)
+}
+
+func walkAttributesFrom(structType reflect.Type, prefix string, nested bool, walking map[reflect.Type]bool, fn func(name string, field reflect.StructField, nested bool)) {
+	// self referencing structs (ie. a linked list) are only descended into once
+	if walking[structType] {
+		return
+	}
+	walking[structType] = true
+	defer delete(walking, structType)
+
+	for x := 0; x < structType.NumField(); x++ {
+		field := structType.Field(x)
+		if field.Anonymous {
+			if embedded := indirectType(field.Type); embedded.Kind() == reflect.Struct && (field.PkgPath == "" || field.Type.Kind() != reflect.Ptr) {
+				walkAttributesFrom(embedded, prefix, true, walking, fn)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := prefix + attributeName(field)
+		elem, placeholder := indirectType(field.Type), ""
+		switch elem.Kind() {
+		case reflect.Slice, reflect.Array:
+			elem, placeholder = indirectType(elem.Elem()), "[<index>]"
+		case reflect.Map:
+			elem, placeholder = indirectType(elem.Elem()), "[<key>]"
+		}
+
+		if elem.Kind() == reflect.Struct && hasExportedFields(elem) {
+			walkAttributesFrom(elem, name+placeholder+".", true, walking, fn)
+			continue
+		}
+		fn(name, field, nested)
+	}
+}
+
+// indirectType returns the type pointed to by pointer types
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// hasExportedFields reports whether the struct has any attributes to set, structs like time.Time are set as a whole rather than descended into
+func hasExportedFields(structType reflect.Type) bool {
+	for x := 0; x < structType.NumField(); x++ {
+		if field := structType.Field(x); field.PkgPath == "" || field.Anonymous {
+			return true
+		}
+	}
+	return false
+}
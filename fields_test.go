@@ -0,0 +1,162 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type DBConfig struct {
+	Host string
+	Port int
+}
+
+type Location struct {
+	Region string
+}
+
+type Deployment struct {
+	Location
+	DB       *DBConfig
+	Primary  DBConfig
+	Servers  []DBConfig
+	Replicas map[string]*DBConfig
+	Labels   map[string]string
+	Ports    []int
+}
+
+func (d *Deployment) Describe() string {
+	var db string
+	if d.DB != nil {
+		db = fmt.Sprintf("%v:%v", d.DB.Host, d.DB.Port)
+	}
+
+	replicas := make([]string, 0, len(d.Replicas))
+	for _, name := range []string{"east", "west"} {
+		if replica, ok := d.Replicas[name]; ok {
+			replicas = append(replicas, name+"="+replica.Host)
+		}
+	}
+	return fmt.Sprintf("region=%v db=%v primary=%v servers=%v replicas=%v labels=%v ports=%v", d.Location.Region, db, d.Primary.Host, d.Servers, replicas, d.Labels, d.Ports)
+}
+
+func TestSplitAttributePath(t *testing.T) {
+	parts, ok := splitAttributePath("Servers[0].Host")
+	expected := []pathPart{{name: "Servers"}, {name: "0", index: true}, {name: "Host"}}
+	if !ok || !reflect.DeepEqual(parts, expected) {
+		t.Errorf("expected the path %+v but got %+v", expected, parts)
+	}
+
+	for _, name := range []string{"", "DB..Host", "Servers[0", ".Host"} {
+		if _, ok := splitAttributePath(name); ok {
+			t.Errorf("expected the path %q to be invalid", name)
+		}
+	}
+}
+
+func TestSetAttributeSliceGrowth(t *testing.T) {
+	deployment := reflect.ValueOf(&Deployment{}).Elem()
+	if _, err := setAttribute(deployment, "Servers[999].Host", false, "a"); err != nil {
+		t.Errorf("Error is not expected but got %v", err)
+	}
+
+	for name, expected := range map[string]error{
+		"Servers[99999999999999].Host": errors.Errorf(SliceIndexTooLargeError, 99999999999999, "[]fuego.DBConfig", maxSliceGrowth),
+		"Ports[1999]":                  errors.Errorf(SliceIndexTooLargeError, 1999, "[]int", maxSliceGrowth),
+	} {
+		found, err := setAttribute(deployment, name, false, "1")
+		if !found || err == nil || !doErrorsMatch(expected, err) {
+			t.Errorf("Expected to receive the first error but instead the second error was returned: \n\t1) \"%v\"\n\t2) \"%v\"", expected, err)
+		}
+	}
+	if servers := deployment.FieldByName("Servers"); servers.Len() != 1000 {
+		t.Errorf("expected the slice to only be grown to the valid index but got %v elements", servers.Len())
+	}
+}
+
+func TestNestedFields(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"db": {"host": "config", "port": 5432}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	arraysPath := filepath.Join(dir, "arrays.json")
+	if err := os.WriteFile(arraysPath, []byte(`{"Servers": [{"Host": "a", "Port": 1000000}, {"Host": "b"}], "Ports": [80, 443]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	mapsPath := filepath.Join(dir, "maps.json")
+	if err := os.WriteFile(mapsPath, []byte(`{"DB": {"Port": 9007199254740993}, "Labels": {"env": "prod", "app.tier": "web"}, "Replicas": {"east": {"Host": "e"}}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	tablesPath := filepath.Join(dir, "tables.toml")
+	if err := os.WriteFile(tablesPath, []byte("Ports = [8080]\n\n[[Servers]]\nHost = \"t\"\nPort = 1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name          string
+		Targets       interface{}
+		Args          []string
+		Expected      interface{}
+		ExpectedError error
+	}{
+		{"NilPointer", &Deployment{}, []string{"Fuego.Fields.NilPointer", "Describe", "--DB.Host=x", "--DB.Port", "5432"}, "region= db=x:5432 primary= servers=[] replicas=[] labels=map[] ports=[]", nil},
+		{"Value", &Deployment{}, []string{"Fuego.Fields.Value", "Describe", "--Primary.Host=p"}, "region= db= primary=p servers=[] replicas=[] labels=map[] ports=[]", nil},
+		{"Embedded", &Deployment{}, []string{"Fuego.Fields.Embedded", "Describe", "--Region=eu"}, "region=eu db= primary= servers=[] replicas=[] labels=map[] ports=[]", nil},
+		{"EmbeddedByName", &Deployment{}, []string{"Fuego.Fields.EmbeddedByName", "Describe", "--Location.Region=us"}, "region=us db= primary= servers=[] replicas=[] labels=map[] ports=[]", nil},
+		{"SliceIndex", &Deployment{}, []string{"Fuego.Fields.SliceIndex", "Describe", "--Servers[1].Host=b", "--Servers[0].Host=a"}, "region= db= primary= servers=[{a 0} {b 0}] replicas=[] labels=map[] ports=[]", nil},
+		{"MapKey", &Deployment{}, []string{"Fuego.Fields.MapKey", "Describe", "--Replicas[west].Host=w", "--Replicas[east].Host=e", "--Labels[env]=prod"}, "region= db= primary= servers=[] replicas=[east=e west=w] labels=map[env:prod] ports=[]", nil},
+		{"ScalarSlice", &Deployment{}, []string{"Fuego.Fields.ScalarSlice", "Describe", "--Ports[0]=80", "--Ports[1]=443"}, "region= db= primary= servers=[] replicas=[] labels=map[] ports=[80 443]", nil},
+		{"Config", &Deployment{}, []string{"Fuego.Fields.Config", "Describe", "--config=" + configPath}, "region= db=config:5432 primary= servers=[] replicas=[] labels=map[] ports=[]", nil},
+		{"ConfigArrays", &Deployment{}, []string{"Fuego.Fields.ConfigArrays", "Describe", "--config=" + arraysPath}, "region= db= primary= servers=[{a 1000000} {b 0}] replicas=[] labels=map[] ports=[80 443]", nil},
+		{"ConfigMaps", &Deployment{}, []string{"Fuego.Fields.ConfigMaps", "Describe", "--config=" + mapsPath}, "region= db=:9007199254740993 primary= servers=[] replicas=[east=e] labels=map[app.tier:web env:prod] ports=[]", nil},
+		{"ConfigArrayOfTables", &Deployment{}, []string{"Fuego.Fields.ConfigArrayOfTables", "Describe", "--config=" + tablesPath}, "region= db= primary= servers=[{t 1}] replicas=[] labels=map[] ports=[8080]", nil},
+		{"UnknownNestedField", &Deployment{}, []string{"Fuego.Fields.UnknownNestedField", "Describe", "--DB.Name=x"}, nil, errors.Errorf(UnknownFlagError, "--DB.Name", "Deployment")},
+		{"InvalidIndex", &Deployment{}, []string{"Fuego.Fields.InvalidIndex", "Describe", "--Servers[x].Host=a"}, nil, errors.Errorf(UnknownFlagError, "--Servers[x].Host", "Deployment")},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			os.Args = testCase.Args
+			values, err := Fuego(testCase.Targets)
+
+			if testCase.ExpectedError != nil {
+				if err == nil || !doErrorsMatch(testCase.ExpectedError, err) {
+					t.Errorf("Expected to receive the first error but instead the second error was returned: \n\t1) \"%v\"\n\t2) \"%v\"", testCase.ExpectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("Error is not expected but got %v", err)
+			} else if len(values) != 1 || !reflect.DeepEqual(values[0].Interface(), testCase.Expected) {
+				t.Errorf("expected %v but got %v", testCase.Expected, values)
+			}
+		})
+	}
+}
+
+func TestWriteHelpNestedFields(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeHelp(&out, &Deployment{}); err != nil {
+		t.Fatal(err)
+	}
+
+	help := strings.Join(strings.Fields(out.String()), " ")
+	for _, expected := range []string{"--Region=<string> Deployment attribute [env DEPLOYMENT_REGION]", "--DB.Host=<string> Deployment attribute [env DEPLOYMENT_DB_HOST]", "--Servers[<index>].Port=<int>", "--Replicas[<key>].Host=<string>", "--Labels=<map[string]string>"} {
+		if !strings.Contains(help, expected) {
+			t.Errorf("expected the help to contain %q but got:\n%v", expected, out.String())
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	UnknownFlagError      = "the flag \"%v\" is not an attribute of struct \"%v\""
+	MissingFlagValueError = "the flag \"%v\" requires a value"
+	InvalidFlagValueError = "the flag \"%v\" could not set the attribute of struct \"%v\""
+)
+
+// flagArg is a struct attribute flag lexed from the command line, ie. --Offset=2, --Offset 2, -o 2, --verbose or --no-verbose
+type flagArg struct {
+	name     string
+	value    string
+	hasValue bool
+	// short flags are matched against the `fuego:"short=<letter>"` alias rather than the attribute name
+	short bool
+}
+
+func (flag flagArg) String() string {
+	if flag.short {
+		return "-" + flag.name
+	}
+	return "--" + flag.name
+}
+
+// lexArgs splits the args into the positional args and the attribute flags of the struct, the flags may be interleaved anywhere among the positional args.
+// Flags are passed GNU style as --<name>=<value>, --<name> <value>, -<short>=<value>, -<short> <value> or -<short><value>, boolean attributes are
+// set without a value (--verbose, -v) and unset with --no-<name>. Every arg after a "--" terminator is positional, as is any "-<x>" that is not a
+// short alias so negative numbers can still be passed as parameters.
+func lexArgs(structType reflect.Type, args []string) ([]string, []flagArg) {
+	var positionals []string
+	var flags []flagArg
+
+	for x := 0; x < len(args); x++ {
+		if args[x] == "--" {
+			return append(positionals, args[x+1:]...), flags
+		}
+
+		flag, ok := lexFlag(structType, args, &x)
+		if !ok {
+			positionals = append(positionals, args[x])
+			continue
+		}
+		flags = append(flags, flag)
+	}
+
+	return positionals, flags
+}
+
+// extractFlags pulls the flags of the struct attributes out of the args, leaving every other arg (including unknown flags and a "--" terminator) in place
+// so the remaining args can be lexed again against another struct, ie. the constructor parameters before the attributes of the constructed struct
+func extractFlags(structType reflect.Type, args []string) ([]flagArg, []string) {
+	var flags []flagArg
+	var remaining []string
+
+	for x := 0; x < len(args); x++ {
+		if args[x] == "--" {
+			return flags, append(remaining, args[x:]...)
+		}
+
+		start := x
+		flag, ok := lexFlag(structType, args, &x)
+		if _, attrType, _ := resolveFlag(structType, flag); !ok || attrType == nil {
+			x = start
+			remaining = append(remaining, args[x])
+			continue
+		}
+		flags = append(flags, flag)
+	}
+
+	return flags, remaining
+}
+
+// lexFlag lexes the flag at args[*x], reporting whether the arg is a flag. The index is advanced past the value when the value is passed as the next arg
+func lexFlag(structType reflect.Type, args []string, x *int) (flagArg, bool) {
+	arg := args[*x]
+
+	var flag flagArg
+	switch {
+	case strings.HasPrefix(arg, "--"):
+		flag.name, flag.value, flag.hasValue = strings.Cut(arg[2:], "=")
+	case len(arg) > 1 && arg[0] == '-' && shortAttributeName(structType, arg[1:2]) != "":
+		flag.name, flag.short = arg[1:2], true
+		if rest := arg[2:]; rest != "" {
+			flag.value, flag.hasValue = strings.TrimPrefix(rest, "="), true
+		}
+	default:
+		return flag, false
+	}
+
+	// attributes that are not booleans take the next arg as their value when it is not passed as --<name>=<value>
+	if _, attrType, _ := resolveFlag(structType, flag); !flag.hasValue && attrType != nil && attrType.Kind() != reflect.Bool && *x+1 < len(args) {
+		*x++
+		flag.value, flag.hasValue = args[*x], true
+	}
+	return flag, true
+}
+
+// resolveFlag resolves the flag to the name of the struct attribute it sets, the type of the attribute (nil when the struct has no such attribute)
+// and the value it is set to, ie. "true" for --verbose and "false" for --no-verbose. The name may be a path into nested structs, ie. --DB.Host
+func resolveFlag(structType reflect.Type, flag flagArg) (string, reflect.Type, string) {
+	name := flag.name
+	if flag.short {
+		if name = shortAttributeName(structType, flag.name); name == "" {
+			return "", nil, ""
+		}
+	}
+
+	attrType, ok := attributeType(structType, name, false)
+	if !ok && !flag.short && strings.HasPrefix(name, "no-") && !flag.hasValue {
+		if negated, ok := attributeType(structType, name[3:], false); ok && negated.Kind() == reflect.Bool {
+			return name[3:], negated, "false"
+		}
+	}
+
+	if !ok {
+		return "", nil, ""
+	} else if attrType.Kind() == reflect.Bool && !flag.hasValue {
+		return name, attrType, "true"
+	}
+	return name, attrType, flag.value
+}
+
+// setFlags sets the struct attributes from the lexed flags. Unknown flags and flags missing their value are errors, while values that can not be
+// converted are reported and skipped to match the attributes set from the config and environment, unless the args come from a remote caller.
+func setFlags(structVal reflect.Value, flags []flagArg, opts *options) error {
+	for _, flag := range flags {
+		name, attrType, value := resolveFlag(structVal.Type(), flag)
+		if attrType == nil {
+			return errors.Errorf(UnknownFlagError, flag, structVal.Type().Name())
+		} else if !flag.hasValue && attrType.Kind() != reflect.Bool {
+			return errors.Errorf(MissingFlagValueError, flag)
+		}
+
+		value, err := opts.expandArgValue(value)
+		if err != nil {
+			return err
+		}
+
+		if _, err := setAttribute(structVal, name, false, value); err != nil {
+			// remote callers get the error rather than a result computed without the attribute, which they would never see reported
+			if !opts.localArgs {
+				return errors.Wrapf(err, InvalidFlagValueError, flag, structVal.Type().Name())
+			}
+			opts.tracef("the flag %v could not set %v.%v: %v", flag, structVal.Type().Name(), name, err)
+			printError(errors.Wrap(err, "the struct attribute could not be altered"))
+			continue
+		}
+		opts.tracef("the flag %v set %v.%v = %q", flag, structVal.Type().Name(), name, value)
+	}
+	return nil
+}
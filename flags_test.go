@@ -0,0 +1,95 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type Greeter struct {
+	Name    string
+	Verbose bool `fuego:"short=v"`
+	Times   int  `fuego:"short=n"`
+	Query   string
+}
+
+func (g *Greeter) Greet(greeting string) string {
+	return fmt.Sprintf("%v %v x%v verbose=%v query=%v", greeting, g.Name, g.Times, g.Verbose, g.Query)
+}
+
+func (g *Greeter) Subtract(a int, b int) int {
+	return a - b
+}
+
+func TestLexArgs(t *testing.T) {
+	positionals, flags := lexArgs(reflect.TypeOf(Greeter{}), []string{"Greet", "--Name", "bob", "hi", "-v", "-n3", "--Query=a=b", "--", "--Name"})
+
+	expectedPositionals := []string{"Greet", "hi", "--Name"}
+	expectedFlags := []flagArg{
+		{name: "Name", value: "bob", hasValue: true},
+		{name: "v", short: true},
+		{name: "n", value: "3", hasValue: true, short: true},
+		{name: "Query", value: "a=b", hasValue: true},
+	}
+	if !reflect.DeepEqual(positionals, expectedPositionals) {
+		t.Errorf("expected the positional args %q but got %q", expectedPositionals, positionals)
+	}
+	if !reflect.DeepEqual(flags, expectedFlags) {
+		t.Errorf("expected the flags %+v but got %+v", expectedFlags, flags)
+	}
+}
+
+func TestFlags(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	testCases := []struct {
+		Name          string
+		Targets       interface{}
+		Args          []string
+		Expected      interface{}
+		ExpectedError error
+	}{
+		{"EqualsValue", &Greeter{}, []string{"Fuego.Flags.EqualsValue", "Greet", "hi", "--Name=bob"}, "hi bob x0 verbose=false query=", nil},
+		{"SpaceValue", &Greeter{}, []string{"Fuego.Flags.SpaceValue", "Greet", "--Name", "bob", "hi"}, "hi bob x0 verbose=false query=", nil},
+		{"ValueWithEquals", &Greeter{}, []string{"Fuego.Flags.ValueWithEquals", "Greet", "hi", "--Query", "a=b&c=d"}, "hi  x0 verbose=false query=a=b&c=d", nil},
+		{"Boolean", &Greeter{}, []string{"Fuego.Flags.Boolean", "Greet", "--Verbose", "hi"}, "hi  x0 verbose=true query=", nil},
+		{"BooleanValue", &Greeter{Verbose: true}, []string{"Fuego.Flags.BooleanValue", "Greet", "--Verbose=false", "hi"}, "hi  x0 verbose=false query=", nil},
+		{"NegatedBoolean", &Greeter{Verbose: true}, []string{"Fuego.Flags.NegatedBoolean", "Greet", "--no-Verbose", "hi"}, "hi  x0 verbose=false query=", nil},
+		{"Short", &Greeter{}, []string{"Fuego.Flags.Short", "Greet", "-v", "-n", "2", "hi"}, "hi  x2 verbose=true query=", nil},
+		{"ShortAttachedValue", &Greeter{}, []string{"Fuego.Flags.ShortAttachedValue", "Greet", "-n2", "hi"}, "hi  x2 verbose=false query=", nil},
+		{"Interleaved", &Greeter{}, []string{"Fuego.Flags.Interleaved", "-v", "Greet", "--Times", "4", "hi", "--Name=bob"}, "hi bob x4 verbose=true query=", nil},
+		{"Terminator", &Greeter{}, []string{"Fuego.Flags.Terminator", "Greet", "--Name=bob", "--", "--Verbose"}, "--Verbose bob x0 verbose=false query=", nil},
+		{"FunctionTerminator", Greet, []string{"Fuego.Flags.FunctionTerminator", "Greet", "--", "--x", "2"}, "hello --x!hello --x!", nil},
+		{"NegativeNumber", &Greeter{}, []string{"Fuego.Flags.NegativeNumber", "Subtract", "-5", "-3"}, -2, nil},
+		{"ValueStruct", Greeter{Times: 1}, []string{"Fuego.Flags.ValueStruct", "Greet", "hi", "--Name=bob", "-v"}, "hi bob x1 verbose=true query=", nil},
+		{"ValueStructInSlice", []interface{}{AddInt, Greeter{}}, []string{"Fuego.Flags.ValueStructInSlice", "Greeter.Greet", "hi", "--Name", "bob"}, "hi bob x0 verbose=false query=", nil},
+		{"UnknownFlag", &Greeter{}, []string{"Fuego.Flags.UnknownFlag", "Greet", "hi", "--Bogus=1"}, nil, errors.Errorf(UnknownFlagError, "--Bogus", "Greeter")},
+		{"MissingValue", &Greeter{}, []string{"Fuego.Flags.MissingValue", "Greet", "hi", "--Name"}, nil, errors.Errorf(MissingFlagValueError, "--Name")},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			os.Args = testCase.Args
+			values, err := Fuego(testCase.Targets)
+
+			if testCase.ExpectedError != nil {
+				if err == nil || !doErrorsMatch(testCase.ExpectedError, err) {
+					t.Errorf("Expected to receive the first error but instead the second error was returned: \n\t1) \"%v\"\n\t2) \"%v\"", testCase.ExpectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("Error is not expected but got %v", err)
+			} else if len(values) != 1 || !reflect.DeepEqual(values[0].Interface(), testCase.Expected) {
+				t.Errorf("expected %v but got %v", testCase.Expected, values)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	InvalidFormatTemplateError = "the output format \"%v\" is not a valid template"
+	FormatExecutionError       = "could not apply the output format to the returned values"
+)
+
+// formatEscapes expands the escape sequences users commonly type in a quoted --format value (ie. '{{.Name}}\t{{.Size}}')
+var formatEscapes = strings.NewReplacer(`\t`, "\t", `\n`, "\n")
+
+// formatFuncs are the helper functions made available to --format templates
+var formatFuncs = template.FuncMap{
+	"json":    formatJSON,
+	"join":    formatJoin,
+	"pad":     formatPad,
+	"padLeft": formatPadLeft,
+}
+
+// parseFormat parses the --format value into a template that is applied to each returned value
+func parseFormat(format string) (*template.Template, error) {
+	tmpl, err := template.New("format").Funcs(formatFuncs).Parse(formatEscapes.Replace(format))
+	if err != nil {
+		return nil, errors.Wrapf(err, InvalidFormatTemplateError, format)
+	}
+	return tmpl, nil
+}
+
+// formatValues executes the template against each returned value, ranging over slices and arrays so the template is applied to every element.
+// A trailing nil error (ie. of func Get(name string) (Item, error)) is not a value to format
+func formatValues(w io.Writer, tmpl *template.Template, values []reflect.Value) error {
+	if last := len(values) - 1; last >= 0 && values[last].IsValid() && values[last].Type() == errorType && values[last].IsNil() {
+		values = values[:last]
+	}
+
+	for _, val := range values {
+		if (val.Kind() == reflect.Slice || val.Kind() == reflect.Array) && val.Type().Elem().Kind() != reflect.Uint8 {
+			for x := 0; x < val.Len(); x++ {
+				if err := formatValue(w, tmpl, val.Index(x)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		if err := formatValue(w, tmpl, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatValue executes the template against a single value and terminates the output with a new line if the template did not
+func formatValue(w io.Writer, tmpl *template.Template, val reflect.Value) error {
+	var data interface{}
+	if val.IsValid() && val.CanInterface() {
+		data = val.Interface()
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return errors.Wrap(err, FormatExecutionError)
+	}
+	if !bytes.HasSuffix(buf.Bytes(), []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// formatJSON is the "json" template helper which renders a value as JSON
+func formatJSON(v interface{}) (string, error) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// formatJoin is the "join" template helper which joins the elements of a slice or array with the separator (ie. {{join ", " .Tags}})
+func formatJoin(sep string, v interface{}) string {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		return fmt.Sprint(v)
+	}
+
+	parts := make([]string, val.Len())
+	for x := range parts {
+		parts[x] = fmt.Sprint(val.Index(x).Interface())
+	}
+	return strings.Join(parts, sep)
+}
+
+// formatPad is the "pad" template helper which left aligns a value by padding it with spaces on the right to the given width
+func formatPad(width int, v interface{}) string {
+	return fmt.Sprintf("%-*v", width, v)
+}
+
+// formatPadLeft is the "padLeft" template helper which right aligns a value by padding it with spaces on the left to the given width
+func formatPadLeft(width int, v interface{}) string {
+	return fmt.Sprintf("%*v", width, v)
+}
@@ -0,0 +1,103 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type FormatFile struct {
+	Name string
+	Size int
+	Tags []string
+}
+
+func TestFormatValues(t *testing.T) {
+	files := []FormatFile{{"a.txt", 12, []string{"x", "y"}}, {"b.txt", 3, nil}}
+
+	testCases := []struct {
+		Name     string
+		Format   string
+		Values   []interface{}
+		Expected string
+	}{
+		{"Scalar", "{{.}}", []interface{}{8}, "8\n"},
+		{"MultipleValues", "[{{.}}]", []interface{}{-0.625, 3}, "[-0.625]\n[3]\n"},
+		{"SliceRange", `{{.Name}}\t{{.Size}}`, []interface{}{files}, "a.txt\t12\nb.txt\t3\n"},
+		{"Join", `{{join "," .Tags}}`, []interface{}{files[0]}, "x,y\n"},
+		{"Pad", `{{pad 6 .Name}}|{{padLeft 3 .Size}}`, []interface{}{files[1]}, "b.txt |  3\n"},
+		{"JSON", `{{json .}}`, []interface{}{files[1]}, `{"Name":"b.txt","Size":3,"Tags":null}` + "\n"},
+		{"TrailingNewLine", "{{.}}\n", []interface{}{"hi"}, "hi\n"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tmpl, err := parseFormat(testCase.Format)
+			if err != nil {
+				t.Fatalf("Error is not expected but got %v", err)
+			}
+
+			values := make([]reflect.Value, len(testCase.Values))
+			for x, val := range testCase.Values {
+				values[x] = reflect.ValueOf(val)
+			}
+
+			var buf bytes.Buffer
+			if err := formatValues(&buf, tmpl, values); err != nil {
+				t.Fatalf("Error is not expected but got %v", err)
+			}
+			if buf.String() != testCase.Expected {
+				t.Errorf("expected the formatted output %q but got %q", testCase.Expected, buf.String())
+			}
+		})
+	}
+}
+
+func FindFormatFile(name string) (FormatFile, error) {
+	return FormatFile{Name: name, Size: 1}, nil
+}
+
+func TestFormatValuesTrailingError(t *testing.T) {
+	tmpl, err := parseFormat("{{.Name}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	values := reflect.ValueOf(FindFormatFile).Call([]reflect.Value{reflect.ValueOf("bob")})
+	if err := formatValues(&buf, tmpl, values); err != nil || buf.String() != "bob\n" {
+		t.Errorf("expected the trailing nil error to be skipped but got %q (%v)", buf.String(), err)
+	}
+}
+
+func TestParseOptionsFormat(t *testing.T) {
+	opts, args, err := parseOptions([]string{"Fuego.TestParseOptionsFormat", "AddInt", "--format", "{{.}}", "3", "--format={{.}}!", "5"})
+	if err != nil {
+		t.Fatalf("Error is not expected but got %v", err)
+	}
+	if !reflect.DeepEqual(args, []string{"Fuego.TestParseOptionsFormat", "AddInt", "3", "5"}) {
+		t.Errorf("the fuego options were not removed from the args: %v", args)
+	}
+
+	var buf bytes.Buffer
+	if err := formatValues(&buf, opts.format, []reflect.Value{reflect.ValueOf(8)}); err != nil || buf.String() != "8!\n" {
+		t.Errorf("the last --format option was not applied, got %q (%v)", buf.String(), err)
+	}
+
+	if _, _, err := parseOptions([]string{"Fuego.TestParseOptionsFormat", "--format={{.Name"}); err == nil || !strings.HasPrefix(err.Error(), fmt.Sprintf(InvalidFormatTemplateError, "{{.Name")) {
+		t.Errorf("expected an invalid format error but got %v", err)
+	}
+
+	if _, _, err := parseOptions([]string{"Fuego.TestParseOptionsFormat", "--format"}); err == nil || !doErrorsMatch(errors.New(MissingOptionValueError), err) {
+		t.Errorf("expected a missing option value error but got %v", err)
+	}
+}
@@ -6,7 +6,9 @@
 package fuego
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"reflect"
 	"runtime"
@@ -20,6 +22,7 @@ const (
 	MethodDoesNotExistError                      = "the method \"%v\" for struct \"%v\" does not exist"
 	UnsupportedTargetTypeError                   = "passing in \"%v\" is not yet supported"
 	InsufficientArgumentsError                   = "not enough arguments were passed in to setup the function parameter values"
+	UnexpectedArgumentsError                     = "more arguments were passed in than the function or method takes, unexpected: %v"
 	ParameterListGenerationError                 = "could not generate the necessary function parameters list"
 	CannotConvertToDesiredValueTypeError         = "cannot convert \"%v\" to \"%v\" as needed"
 	UnsupportedConversionToDesiredValueTypeError = "fuego does not yet support converting attributes of type \"%v\""
@@ -34,90 +37,180 @@ var (
 
 // Fuego handles the parsing of potential targets to call and then reflectively calls the function with all necessary params
 func Fuego(targets interface{}) ([]reflect.Value, error) {
-	osArgs := os.Args
+	return New(targets).Run()
+}
+
+// run parses the fuego options out of the command line on top of the options set by the app and runs the targets in the selected mode
+func run(targets interface{}, opts *options) ([]reflect.Value, error) {
+	opts.localArgs = true
+	opts.attributes = attributeFlags(targets)
+	args, err := opts.parse(os.Args)
+	if err != nil {
+		printError(err)
+		return nil, err
+	}
+
+	// struct attributes are set from their defaults, the config and then the environment so that --<attribute>=<value> flags take precedence over all of them
+	targets = applyDefaults(targets)
+	targets, err = applyConfig(targets, opts)
+	if err != nil {
+		printError(err)
+		return nil, err
+	}
+	targets = applyEnv(targets)
+
+	if opts.help {
+		return nil, writeHelp(os.Stdout, targets)
+	} else if opts.interactive {
+		return nil, runInteractive(targets, opts)
+	} else if opts.script != "" {
+		return nil, runScriptFile(targets, opts)
+	} else if opts.each {
+		return nil, runEach(targets, args, opts, opts.input(), os.Stdout)
+	} else if opts.serve != "" {
+		return nil, runServer(targets, opts)
+	} else if opts.openAPI {
+		return nil, writeOpenAPI(os.Stdout, targets)
+	} else if opts.jsonRPC {
+		return nil, runJSONRPC(targets, opts)
+	} else if opts.tools {
+		return nil, writeTools(os.Stdout, targets)
+	}
+
+	return fuegoPrintWrapper(opts)(dispatch(targets, args, opts))
+}
+
+// dispatch is used as a helper function for Fuego() to call the appropriate target based on the type of targets provided and the args passed in
+func dispatch(targets interface{}, args []string, opts *options) ([]reflect.Value, error) {
+	if c, ok := targets.(*constructor); ok {
+		return fuegoConstructor(c, args, opts)
+	}
+
 	targetType := reflect.TypeOf(targets)
+	opts.tracef("dispatching %q on the %v target %v", args[1:], targetType.Kind(), targetType)
 
 	switch targetType.Kind() {
 	case reflect.Func:
-		return fuegoPrintWrapper(fuegoFunc(targets, osArgs))
+		return fuegoFunc(targets, args, opts)
 	case reflect.Ptr, reflect.Struct:
-		return fuegoPrintWrapper(fuegoStruct(targets, osArgs))
+		return fuegoStruct(targets, args, opts)
 	case reflect.Array, reflect.Slice:
-		if len(osArgs) < 2 {
+		if len(args) < 2 {
 			return nil, errors.Errorf(InsufficientArgumentsError)
 		}
 
-		methodTitleName := strings.Title(osArgs[1])
+		methodTitleName := strings.Title(args[1])
 
 		// foreach element in the slice of targets provided, check to see if this is what was called by the cli
 		// if so call this function passing in the element as the new target
 		for _, key := range targets.([]interface{}) {
 			keyType := reflect.TypeOf(key)
 
-			if keyType.Kind() == reflect.Func && functionName(key) == methodTitleName {
-				return Fuego(key)
+			if c, ok := key.(*constructor); ok {
+				if strings.HasPrefix(methodTitleName, c.structType.Name()+".") {
+					opts.tracef("%q matched the constructor %v of %v", args[1], c.name, c.structType.Name())
+					return dispatch(key, args, opts)
+				}
+			} else if keyType.Kind() == reflect.Func && functionName(key) == methodTitleName {
+				opts.tracef("%q matched the function %v", args[1], functionName(key))
+				return dispatch(key, args, opts)
 			} else if keyType.Kind() == reflect.Struct && strings.HasPrefix(methodTitleName, keyType.Name()+".") {
-				return Fuego(key)
+				opts.tracef("%q matched the struct %v", args[1], keyType.Name())
+				return dispatch(key, args, opts)
 			} else if keyType.Kind() == reflect.Ptr && keyType.Elem().Kind() == reflect.Struct && strings.HasPrefix(methodTitleName, keyType.Elem().Name()+".") {
-				return Fuego(key)
+				opts.tracef("%q matched the struct %v", args[1], keyType.Elem().Name())
+				return dispatch(key, args, opts)
 			}
 		}
 
 		return nil, errors.Errorf(UnsupportedTargetTypeError, methodTitleName)
 	default:
-		err := errors.Errorf(UnsupportedTargetTypeError, targetType.Kind())
-		printError(err)
-		return nil, err
+		return nil, errors.Errorf(UnsupportedTargetTypeError, targetType.Kind())
 	}
 }
 
 // fuegoFunc is used as a helper function for Fuego() to handle targets of type Func
-func fuegoFunc(target interface{}, args []string) ([]reflect.Value, error) {
+func fuegoFunc(target interface{}, args []string, opts *options) ([]reflect.Value, error) {
 	targetVal := reflect.ValueOf(target)
-	targetFuncName := runtime.FuncForPC(targetVal.Pointer()).Name()
-	targetFuncName = targetFuncName[strings.LastIndex(targetFuncName, ".")+1:]
-	// targetFuncParamCount := targetVal.Type().NumIn()
+	targetFuncName := functionName(target)
+	// a leading context.Context parameter is injected by fuego rather than parsed from the args
+	paramOffset := 0
+	if acceptsContext(targetVal.Type()) {
+		paramOffset = 1
+	}
 
-	targetFuncParamCount := targetVal.Type().NumIn()
+	targetFuncParamCount := targetVal.Type().NumIn() - paramOffset
 
-	if len(args) > 1 && args[1] == targetFuncName && len(args)-2 < targetFuncParamCount {
-		// the function name is explicitly called out but not enough params passed in
-		return nil, errors.Errorf(InsufficientArgumentsError)
-	} else if len(args) > 1 && args[1] != targetFuncName && len(args)-1 < targetFuncParamCount {
-		// the function name is not passed is and there are not enough params passed in
-		return nil, errors.Errorf(InsufficientArgumentsError)
+	// the function name may be explicitly called out before the params
+	var params []string
+	if len(args) > 1 && args[1] == targetFuncName {
+		params = args[2:]
+	} else if len(args) > 1 {
+		params = args[1:]
+	}
+	// functions take no flags so a "--" terminator is only dropped, every arg after it is positional like the args of struct methods
+	for x, param := range params {
+		if param == "--" {
+			params = append(params[:x:x], params[x+1:]...)
+			break
+		}
 	}
 
-	var funcParams []reflect.Value
-	var err error
+	paramTypes := make([]reflect.Type, targetFuncParamCount)
+	for x := range paramTypes {
+		paramTypes[x] = targetVal.Type().In(x + paramOffset)
+	}
 
-	if targetFuncParamCount > 0 {
-		targetValKind := make([]reflect.Kind, targetFuncParamCount)
+	opts.tracef("the function %v takes %v parameters, %v were passed in", targetFuncName, targetFuncParamCount, len(params))
+	if len(params) < targetFuncParamCount {
+		// params that are not passed in can be bound to environment variables, ie. ADDINT_B
+		paramNames := envParamNames(describeFunc(targetVal), paramOffset+len(params))
+		opts.tracef("looking up the parameters %v in the environment", paramNames)
+		params = append(params, envParams(envPrefix(targetFuncName), paramNames)...)
+	}
+	params, err := opts.defaultStreamParams(paramTypes, params)
+	if err != nil {
+		return nil, err
+	}
 
-		for x := 0; x < targetFuncParamCount; x++ {
-			// parameterVal := reflect.ValueOf(args[x])
-			targetValKind[x] = targetVal.Type().In(x).Kind()
-		}
+	if len(params) < targetFuncParamCount {
+		return nil, errors.Errorf(InsufficientArgumentsError)
+	} else if len(params) > targetFuncParamCount {
+		return nil, errors.Errorf(UnexpectedArgumentsError, strings.Join(params[targetFuncParamCount:], " "))
+	}
 
-		if len(args) > 2 && args[1] == targetFuncName {
-			funcParams, err = convertStringsToReflectValues(targetValKind, args[2:])
-			if err != nil {
-				return nil, errors.Wrap(err, ParameterListGenerationError)
-			}
-		} else if len(args) > 1 && args[1] != targetFuncName {
-			funcParams, err = convertStringsToReflectValues(targetValKind, args[1:])
-			if err != nil {
-				return nil, errors.Wrap(err, ParameterListGenerationError)
-			}
-		}
+	funcParams, streams, err := opts.convertParams(paramTypes, params)
+	if err != nil {
+		return nil, err
 	}
 
-	return targetVal.Call(funcParams), nil
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if paramOffset > 0 {
+		ctx, cancel = opts.newContext()
+		funcParams = append([]reflect.Value{reflect.ValueOf(ctx)}, funcParams...)
+	}
+
+	// the context is cancelled and the files opened for io.Reader and io.Writer params are closed (flushing any gzip writers) once the call returns,
+	// or once the channels and iterators it returns are drained
+	values, err := opts.call(Call{Target: targetFuncName, Args: funcParams}, targetVal)
+	return releaseCall(ctx, values, err, func() error {
+		if cancel != nil {
+			cancel()
+		}
+		return closeStreams(streams)
+	})
 }
 
 // fuegoStruct is used as a helper function for Fuego() to handle targets of type Struct or pointer to a Struct
-func fuegoStruct(target interface{}, args []string) ([]reflect.Value, error) {
-	targetVal := reflect.ValueOf(reflect.ValueOf(&target).Elem().Interface())
+func fuegoStruct(target interface{}, args []string, opts *options) ([]reflect.Value, error) {
+	targetVal := reflect.ValueOf(target)
+	// struct values are copied into a pointer so their attributes can be set by flags and the methods of both the value and pointer method sets can be called
+	if targetVal.Kind() == reflect.Struct {
+		ptr := reflect.New(targetVal.Type())
+		ptr.Elem().Set(targetVal)
+		targetVal = ptr
+	}
 
 	structNameSplit := strings.Split(targetVal.Type().String(), ".")
 	structName := structNameSplit[len(structNameSplit)-1]
@@ -127,25 +220,16 @@ func fuegoStruct(target interface{}, args []string) ([]reflect.Value, error) {
 		return nil, errors.Errorf(InsufficientArgumentsError)
 	}
 
-	for _, arg := range args {
-		if strings.HasPrefix(arg, "--") && len(arg) > 2 {
-			arg = arg[2:]
-			argSplit := strings.Split(arg, "=")
-			attribute := targetVal.Elem().FieldByName(argSplit[0])
-
-			if attribute.CanSet() {
-				vals, err := convertStringsToReflectValues([]reflect.Kind{attribute.Kind()}, []string{argSplit[1]})
-				if err != nil {
-					// do i error out or ignore and continue and print the error - leaning to fail
-					printError(errors.Wrap(err, "the struct attribute could not be altered"))
-					continue
-				}
-				attribute.Set(vals[0])
-			}
-		}
+	// the struct attribute flags are lexed out of the args, leaving the method name followed by its parameters
+	positionals, flags := lexArgs(targetVal.Elem().Type(), args[1:])
+	if len(positionals) < 1 {
+		return nil, errors.Errorf(InsufficientArgumentsError)
+	}
+	if err := setFlags(targetVal.Elem(), flags, opts); err != nil {
+		return nil, err
 	}
 
-	methodName := args[1]
+	methodName := positionals[0]
 	if strings.Contains(methodName, structName) {
 		methodName = methodName[strings.LastIndex(methodName, ".")+1:]
 	}
@@ -154,64 +238,132 @@ func fuegoStruct(target interface{}, args []string) ([]reflect.Value, error) {
 	if !method.IsValid() {
 		return nil, errors.Errorf(MethodDoesNotExistError, methodName, structName)
 	}
+	opts.tracef("%q matched the method %v.%v", positionals[0], structName, methodName)
 
-	targetMethodParamCount := method.Type().NumIn()
+	if err := validateRequired(targetVal.Elem()); err != nil {
+		return nil, err
+	}
+	if err := validateTarget(targetVal, structName); err != nil {
+		return nil, err
+	}
 
-	if len(args)-2 < targetMethodParamCount {
-		return nil, errors.New(InsufficientArgumentsError)
+	// a leading context.Context parameter is injected by fuego rather than parsed from the args
+	paramOffset := 0
+	if acceptsContext(method.Type()) {
+		paramOffset = 1
 	}
 
-	var funcParams []reflect.Value
-	var err error
+	targetMethodParamCount := method.Type().NumIn() - paramOffset
+	paramTypes := make([]reflect.Type, targetMethodParamCount)
+	for x := range paramTypes {
+		paramTypes[x] = method.Type().In(x + paramOffset)
+	}
 
-	if targetMethodParamCount > 0 {
-		targetValKind := make([]reflect.Kind, targetMethodParamCount)
+	params := positionals[1:]
+	opts.tracef("the method %v.%v takes %v parameters, %v were passed in", structName, methodName, targetMethodParamCount, len(params))
+	if len(params) < targetMethodParamCount {
+		// params that are not passed in can be bound to environment variables, ie. MYMATH_B
+		paramNames := envParamNames(describeMethod(targetVal.Type(), methodName), paramOffset+len(params))
+		opts.tracef("looking up the parameters %v in the environment", paramNames)
+		params = append(params, envParams(envPrefix(structName), paramNames)...)
+	}
+	params, err := opts.defaultStreamParams(paramTypes, params)
+	if err != nil {
+		return nil, err
+	}
 
-		for x := 0; x < targetMethodParamCount; x++ {
-			targetValKind[x] = method.Type().In(x).Kind()
-		}
+	if len(params) < targetMethodParamCount {
+		return nil, errors.New(InsufficientArgumentsError)
+	} else if len(params) > targetMethodParamCount {
+		return nil, errors.Errorf(UnexpectedArgumentsError, strings.Join(params[targetMethodParamCount:], " "))
+	}
 
-		funcParams, err = convertStringsToReflectValues(targetValKind, args[2:])
-		if err != nil {
-			return nil, errors.Wrap(err, ParameterListGenerationError)
-		}
+	funcParams, streams, err := opts.convertParams(paramTypes, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if paramOffset > 0 {
+		ctx, cancel = opts.newContext()
+		funcParams = append([]reflect.Value{reflect.ValueOf(ctx)}, funcParams...)
 	}
 
-	return method.Call(funcParams), nil
+	// the target is initialized right before the call and closed after it, even when the initialization or the call fails. The context is cancelled,
+	// the files opened for io.Reader and io.Writer params are closed (flushing any gzip writers) and the target is closed once the call returns,
+	// or once the channels and iterators it returns are drained
+	var values []reflect.Value
+	if err = initTarget(targetVal, structName); err == nil {
+		values, err = opts.call(Call{Target: structName, Method: methodName, Args: funcParams}, method)
+	}
+	return releaseCall(ctx, values, err, func() error {
+		if cancel != nil {
+			cancel()
+		}
+		err := closeStreams(streams)
+		if closeErr := closeTarget(targetVal, structName); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		if opts.release != nil {
+			opts.release()
+		}
+		return err
+	})
 }
 
+// functionName returns the name a function is called by, ie. "Add" for both the function Add and the method value MyMath{}.Add
 func functionName(key interface{}) string {
-	funcName := runtime.FuncForPC(reflect.ValueOf(key).Pointer()).Name()
+	funcName := strings.TrimSuffix(runtime.FuncForPC(reflect.ValueOf(key).Pointer()).Name(), "-fm")
 	return funcName[strings.LastIndex(funcName, ".")+1:]
 }
 
-// printValues is used to handle printing out Reflect Values to std out if the user would like to allow it
-func printValues(values []reflect.Value) {
+// printValues is used to handle printing out Reflect Values to the writer (std out) if the user would like to allow it
+func printValues(w io.Writer, values []reflect.Value, opts *options) error {
 	if PrintToStdOut {
+		if hasStreamResults(values) {
+			return printStreams(w, values, opts)
+		}
+		if opts.format != nil {
+			return formatValues(w, opts.format, values)
+		}
+
 		for x, val := range values {
-			fmt.Print(val.Interface())
+			fmt.Fprint(w, val.Interface())
 			if x < len(values)-1 {
-				fmt.Print(", ")
+				fmt.Fprint(w, ", ")
 			}
 		}
 	}
+	return nil
 }
 
 // printError is used to handle printing out an Error to std err if the user would like to allow it
 func printError(err error) {
 	if PrintToStdErr {
-		_, _ = os.Stderr.WriteString("Error: " + err.Error())
+		_, _ = os.Stderr.WriteString("Error: " + err.Error() + "\n")
 	}
 }
 
-// fuegoPrintWrapper is a simple wrapper function to parse the results and error that Fuego would return and print it out to std out / std err if desired
-func fuegoPrintWrapper(values []reflect.Value, err error) ([]reflect.Value, error) {
-	if err != nil {
-		printError(err)
-	} else {
-		printValues(values)
+// printStack is used to print the stack trace of a recovered panic to std err (or with the rest of the --trace) when --debug or --trace is set
+func printStack(err error, opts *options) {
+	if panicErr, ok := errors.Cause(err).(*PanicError); ok && (opts.debug || opts.trace) && PrintToStdErr {
+		_, _ = opts.diagnostics().Write(panicErr.Stack)
+	}
+}
+
+// fuegoPrintWrapper returns a simple wrapper function to parse the results and error that Fuego would return and print it out to std out / std err if desired
+func fuegoPrintWrapper(opts *options) func([]reflect.Value, error) ([]reflect.Value, error) {
+	return func(values []reflect.Value, err error) ([]reflect.Value, error) {
+		if err == nil {
+			err = printValues(os.Stdout, values, opts)
+		}
+		if err != nil {
+			printError(err)
+			printStack(err, opts)
+		}
+		return values, err
 	}
-	return values, err
 }
 
 // convertStringsToReflectValues converts a list of strings to the desired reflect value so that it can be used as a parameter for a reflective call of a function or to be set as the value of a struct attribute.
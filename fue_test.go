@@ -6,6 +6,7 @@
 package fuego
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"os"
@@ -161,14 +162,14 @@ var (
 			nil,
 		},
 		{
-			"SliceFunctionsWithAdditionalArgs.Success",
+			"SliceFunctionsWithAdditionalArgs.Failure",
 			[]interface{}{AddInt, SubtractInt, MyMath{Offset: 0}},
-			[]string{"Fuego.SliceFunctionsWithAdditionalArgs.Success", "SubtractInt", "5", "4", "3", "5"},
+			[]string{"Fuego.SliceFunctionsWithAdditionalArgs.Failure", "SubtractInt", "5", "4", "3", "5"},
 			false,
 			false,
-			reflect.ValueOf(SubtractInt).Type().NumOut(),
-			[]interface{}{1},
+			0,
 			nil,
+			errors.Errorf(UnexpectedArgumentsError, "3 5"),
 		},
 		{
 			"SliceFunctionsWithNotEnoughArgs1.Failure",
@@ -221,14 +222,14 @@ var (
 			nil,
 		},
 		{
-			"SliceStructWithAdditionalArgs.Success",
+			"SliceStructWithAdditionalArgs.Failure",
 			[]interface{}{AddInt, SubtractInt, MyMath{Offset: 0}},
-			[]string{"Fuego.SliceStructWithAdditionalArgs.Success", "MyMath.Subtract", "5", "4", "3", "5"},
+			[]string{"Fuego.SliceStructWithAdditionalArgs.Failure", "MyMath.Subtract", "5", "4", "3", "5"},
 			false,
 			false,
-			reflect.ValueOf(MyMath{Offset: 0}.Subtract).Type().NumOut(),
-			[]interface{}{float64(1)},
+			0,
 			nil,
+			errors.Errorf(UnexpectedArgumentsError, "3 5"),
 		},
 		{
 			"SliceStructWithNotEnoughArgs1.Failure",
@@ -281,6 +282,26 @@ var (
 			nil,
 		},
 
+		{
+			"StructAttributeBeforeParams.Success",
+			[]interface{}{&(MyMath{Offset: 5})},
+			[]string{"Fuego.StructAttributeBeforeParams.Success", "MyMath.Add", "--Offset=1", "5", "3"},
+			false,
+			false,
+			reflect.ValueOf(MyMath{Offset: 0}.Add).Type().NumOut(),
+			[]interface{}{float64(9)},
+			nil,
+		},
+		{
+			"StructValueAttributeArgument.Success",
+			[]interface{}{MyMath{Offset: 5}},
+			[]string{"Fuego.StructValueAttributeArgument.Success", "MyMath.Add", "5", "3", "--Offset=2"},
+			false,
+			false,
+			reflect.ValueOf(MyMath{Offset: 0}.Add).Type().NumOut(),
+			[]interface{}{float64(10)},
+			nil,
+		},
 		{
 			"StructAttributeInvalidTypeArgument.Success",
 			[]interface{}{&(MyMath{Offset: 5})},
@@ -291,6 +312,66 @@ var (
 			[]interface{}{float64(13)},
 			nil,
 		},
+		{
+			"FunctionContext.Success",
+			AddIntWithContext,
+			[]string{"Fuego.FunctionContext.Success", "3", "5"},
+			false,
+			false,
+			reflect.ValueOf(AddIntWithContext).Type().NumOut(),
+			[]interface{}{int(8), false},
+			nil,
+		},
+		{
+			"FunctionContextTimeout.Success",
+			AddIntWithContext,
+			[]string{"Fuego.FunctionContextTimeout.Success", "AddIntWithContext", "3", "--timeout=1m", "5"},
+			false,
+			false,
+			reflect.ValueOf(AddIntWithContext).Type().NumOut(),
+			[]interface{}{int(8), true},
+			nil,
+		},
+		{
+			"FunctionContextInsufficientArguments.Failure",
+			AddIntWithContext,
+			[]string{"Fuego.FunctionContextInsufficientArguments.Failure", "3"},
+			false,
+			false,
+			0,
+			nil,
+			errors.New(InsufficientArgumentsError),
+		},
+		{
+			"StructContext.Success",
+			MyMath{Offset: 1},
+			[]string{"Fuego.StructContext.Success", "MyMath.Multiply", "2", "3", "--timeout", "1m"},
+			false,
+			false,
+			reflect.ValueOf(MyMath{}.Multiply).Type().NumOut(),
+			[]interface{}{float64(7)},
+			nil,
+		},
+		{
+			"FunctionInvalidTimeout.Failure",
+			AddIntWithContext,
+			[]string{"Fuego.FunctionInvalidTimeout.Failure", "3", "5", "--timeout=soon"},
+			false,
+			false,
+			0,
+			nil,
+			errors.New(InvalidOptionValueError),
+		},
+		{
+			"FunctionPanic.Failure",
+			DivideInt,
+			[]string{"Fuego.FunctionPanic.Failure", "DivideInt", "5", "0", "--debug"},
+			false,
+			false,
+			0,
+			nil,
+			errors.Errorf(TargetPanicError, "DivideInt", "runtime error: integer divide by zero"),
+		},
 	}
 )
 
@@ -373,7 +454,7 @@ func TestConvertStringsToReflectValues(t *testing.T) {
 	}
 
 	if _, err := convertStringsToReflectValues(successKind, successArgs); err != nil {
-		t.Errorf(err.Error())
+		t.Error(err)
 	}
 
 	for _, kind := range successKind {
@@ -401,6 +482,15 @@ func SubtractInt(a int, b int) int {
 	return a - b
 }
 
+func DivideInt(a int, b int) int {
+	return a / b
+}
+
+func AddIntWithContext(ctx context.Context, a int, b int) (int, bool) {
+	_, hasDeadline := ctx.Deadline()
+	return a + b, hasDeadline
+}
+
 type MyMath struct {
 	Offset float64
 }
@@ -413,6 +503,13 @@ func (m MyMath) Subtract(a float64, b float64) float64 {
 	return a - b - m.Offset
 }
 
+func (m MyMath) Multiply(ctx context.Context, a float64, b float64) float64 {
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		return 0
+	}
+	return a*b + m.Offset
+}
+
 func doErrorsMatch(err1 error, err2 error) bool {
 	if err1.Error() == err2.Error() {
 		return true
@@ -0,0 +1,12 @@
+module github.com/irasekh3/fuego
+
+go 1.19
+
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/chzyer/readline v1.5.1
+	github.com/pkg/errors v0.9.1
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require golang.org/x/sys v0.0.0-20220310020820-b874c991c1a5 // indirect
@@ -0,0 +1,136 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// optionUsage describes the fuego options for --help, in the order they are listed
+var optionUsage = [][2]string{
+	{"--help", "print this help"},
+	{"--format=<template>", "format each returned value with a go template, ie. '{{.Name}}\\t{{.Size}}'"},
+	{"--timeout=<duration>", "set a deadline on the context passed to the target"},
+	{"--debug", "print the stack trace when the target panics"},
+	{"--trace", "print how the command is resolved, the attributes set by flags, the converted arguments, the results and the stack trace of a panic"},
+	{"--time", "print the wall time, cpu time and allocations of the call"},
+	{"--repeat=<n>", "call the target n times and print summary statistics of the timings"},
+	{"--cpuprofile=<path>", "write a cpu profile of the call for go tool pprof"},
+	{"--memprofile=<path>", "write a heap profile after the call for go tool pprof"},
+	{"--trace-out=<path>", "write a runtime execution trace of the call for go tool trace"},
+	{"--config=<path>", "load struct attributes from a JSON, YAML or TOML file"},
+	{"--args-file=<path|->", "read more args from a file or std in, one or more shell quoted args per line"},
+	{"--interactive", "open a REPL over the targets"},
+	{"--script=<path|->", "run one command per line from a file or std in"},
+	{"--each", "call the command once per line (or JSON record) read from std in, appending it to the args"},
+	{"--jobs=<n>", "call up to n --each records at once"},
+	{"--unordered", "write the --each results as they complete rather than in input order"},
+	{"--stop-on-error", "stop the --script or --each at the first failing line"},
+	{"--serve=<address>", "serve the targets as a JSON API, ie. --serve=:8080"},
+	{"--openapi", "print the OpenAPI document of the JSON API"},
+	{"--jsonrpc", "serve JSON-RPC 2.0 requests over std in / std out"},
+	{"--tools", "print the tool definitions for LLM agents"},
+}
+
+// writeHelp writes the usage of the targets, listing the commands with their parameters and the struct attributes with their environment variables
+func writeHelp(w io.Writer, targets interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintf(tw, "Usage: %v <command> [<parameter>...] [--<attribute>=<value>...]\n", filepath.Base(os.Args[0]))
+
+	fmt.Fprintln(tw, "\nCommands:")
+	for _, cmd := range listCommands(targets) {
+		paramNames, paramTypes := cmd.Params()
+
+		prefix := envPrefix(cmd.Name)
+		if cmd.Struct != nil {
+			prefix = envPrefix(cmd.Struct.Name())
+		}
+
+		usage := cmd.Name
+		for x, paramName := range paramNames {
+			usage += fmt.Sprintf(" <%v %v>", paramName, paramTypes[x])
+		}
+
+		var envNames []string
+		for _, paramName := range envParamNames(cmd.Doc, len(cmd.Doc.Params)-len(paramNames)) {
+			envNames = append(envNames, prefix+"_"+envName(paramName))
+		}
+
+		description := strings.SplitN(cmd.Doc.Doc, "\n", 2)[0]
+		if len(envNames) > 0 {
+			description = strings.TrimSpace(description + " [env " + strings.Join(envNames, ", ") + "]")
+		}
+		fmt.Fprintf(tw, "  %v\t%v\n", usage, description)
+	}
+
+	hasAttributes := false
+	for _, target := range targetList(targets) {
+		// the parameters of a constructor are listed before the attributes of the struct it builds, which are only set by flags
+		constructed := false
+		if c, ok := target.(*constructor); ok {
+			walkAttributes(c.paramsType, func(name string, field reflect.StructField, nested bool) {
+				if !hasAttributes {
+					fmt.Fprintln(tw, "\nAttributes:")
+					hasAttributes = true
+				}
+				fmt.Fprintf(tw, "  --%v=<%v>\t%v parameter\n", name, field.Type, c.name)
+			})
+			target, constructed = reflect.New(c.structType).Interface(), true
+		}
+
+		structType := reflect.TypeOf(target)
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+		if structType.Kind() != reflect.Struct {
+			continue
+		}
+
+		// nested and embedded attributes are listed by their path, ie. --DB.Host, only top level attributes have shorts and are required
+		walkAttributes(structType, func(name string, field reflect.StructField, nested bool) {
+			if !hasAttributes {
+				fmt.Fprintln(tw, "\nAttributes:")
+				hasAttributes = true
+			}
+			tag := parseFuegoTag(field)
+			flag := "--" + name
+			if tag.short != "" && !nested {
+				flag += ", -" + tag.short
+			}
+
+			description := tag.usage
+			if description == "" {
+				description = structType.Name() + " attribute"
+			}
+			if constructed || isElementPath(name) {
+				fmt.Fprintf(tw, "  %v=<%v>\t%v\n", flag, field.Type, description)
+				return
+			}
+
+			if tag.hasDefault {
+				description += fmt.Sprintf(" (default %v)", tag.def)
+			}
+			if tag.required && !nested {
+				description += " [required]"
+			}
+			fmt.Fprintf(tw, "  %v=<%v>\t%v [env %v]\n", flag, field.Type, description, attributeEnvName(structType, name, field))
+		})
+	}
+
+	fmt.Fprintln(tw, "\nOptions:")
+	for _, usage := range optionUsage {
+		fmt.Fprintf(tw, "  %v\t%v\n", usage[0], usage[1])
+	}
+
+	return tw.Flush()
+}
@@ -0,0 +1,267 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/chzyer/readline"
+	"github.com/pkg/errors"
+)
+
+const (
+	UnknownVariableError = "the variable \"%v\" has not been set"
+)
+
+// errExitInteractive is returned by a repl command that ends the --interactive session
+var errExitInteractive = errors.New("exit")
+
+// repl holds the state of an --interactive session so that struct field changes and returned values persist between commands
+type repl struct {
+	programName string
+	targets     interface{}
+	opts        *options
+	vars        []reflect.Value
+	out         io.Writer
+}
+
+// newREPL creates the session state for the targets, copying struct values so field changes persist between commands
+func newREPL(programName string, targets interface{}, opts *options, out io.Writer) *repl {
+	return &repl{
+		programName: programName,
+		targets:     addressableTargets(targets),
+		opts:        opts,
+		out:         out,
+	}
+}
+
+// runInteractive opens a line editing REPL over the targets where each line is dispatched like a command line
+func runInteractive(targets interface{}, opts *options) error {
+	session := newREPL(os.Args[0], targets, opts, os.Stdout)
+
+	rl, err := readline.NewEx(&readline.Config{
+		Prompt:          filepath.Base(session.programName) + "> ",
+		HistoryFile:     historyFilePath(session.programName),
+		AutoComplete:    session,
+		InterruptPrompt: "^C",
+		EOFPrompt:       "exit",
+	})
+	if err != nil {
+		return err
+	}
+	defer rl.Close()
+
+	for {
+		line, err := rl.Readline()
+		if err == readline.ErrInterrupt {
+			if len(line) == 0 {
+				return nil
+			}
+			continue
+		} else if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := session.execute(line); err == errExitInteractive {
+			return nil
+		} else if err != nil && PrintToStdErr {
+			_, _ = fmt.Fprintln(rl.Stderr(), "Error: "+err.Error())
+		}
+	}
+}
+
+// execute dispatches a single line of the session, printing and storing the returned values as numbered variables ($1, $2, ...)
+func (r *repl) execute(line string) error {
+	args, err := splitCommandLine(line)
+	if err != nil || len(args) == 0 {
+		return err
+	}
+
+	if args[0] == "exit" || args[0] == "quit" {
+		return errExitInteractive
+	}
+
+	for x, arg := range args {
+		if args[x], err = r.expandVariable(arg); err != nil {
+			return err
+		}
+	}
+
+	lineOpts := *r.opts
+	lineOpts.interactive = false
+	args, err = lineOpts.parse(append([]string{r.programName}, args...))
+	if err != nil {
+		return err
+	}
+
+	values, err := dispatch(r.targets, args, &lineOpts)
+	if err != nil {
+		return err
+	}
+
+	// streams are drained rather than stored as they can only be read once
+	if hasStreamResults(values) {
+		return printStreams(r.out, values, &lineOpts)
+	}
+
+	if lineOpts.format != nil {
+		r.vars = append(r.vars, values...)
+		return formatValues(r.out, lineOpts.format, values)
+	}
+
+	for _, val := range values {
+		r.vars = append(r.vars, val)
+		if _, err := fmt.Fprintf(r.out, "$%d = %v\n", len(r.vars), val.Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandVariable replaces an arg of the form $<n> with the value returned by an earlier command
+func (r *repl) expandVariable(arg string) (string, error) {
+	if !strings.HasPrefix(arg, "$") {
+		return arg, nil
+	}
+
+	index, err := strconv.Atoi(arg[1:])
+	if err != nil {
+		return arg, nil
+	}
+	if index < 1 || index > len(r.vars) {
+		return "", errors.Errorf(UnknownVariableError, arg)
+	}
+	return fmt.Sprint(r.vars[index-1].Interface()), nil
+}
+
+// Do implements readline.AutoCompleter, completing the command (functions and methods) for the first word and struct fields for flags
+func (r *repl) Do(line []rune, pos int) ([][]rune, int) {
+	typed := string(line[:pos])
+	word := typed[strings.LastIndexAny(typed, " \t")+1:]
+
+	var candidates []string
+	if strings.HasPrefix(word, "-") {
+		candidates = targetFieldFlags(r.targets)
+	} else if strings.TrimSpace(typed) == word {
+		candidates = targetCommands(r.targets)
+	}
+
+	var completions [][]rune
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			completions = append(completions, []rune(candidate[len(word):]))
+		}
+	}
+	return completions, len([]rune(word))
+}
+
+// addressableTargets replaces struct values within the targets with pointers to copies of them so that field changes are kept on a single shared instance
+func addressableTargets(targets interface{}) interface{} {
+	switch t := targets.(type) {
+	case []interface{}:
+		addressable := make([]interface{}, len(t))
+		for x, target := range t {
+			addressable[x] = addressableTargets(target)
+		}
+		return addressable
+	default:
+		targetVal := reflect.ValueOf(targets)
+		if targetVal.Kind() != reflect.Struct {
+			return targets
+		}
+
+		ptr := reflect.New(targetVal.Type())
+		ptr.Elem().Set(targetVal)
+		return ptr.Interface()
+	}
+}
+
+// targetCommands lists the names that can be used to call each function and struct method within the targets
+func targetCommands(targets interface{}) []string {
+	var commands []string
+
+	switch t := targets.(type) {
+	case []interface{}:
+		for _, target := range t {
+			for _, command := range targetCommands(target) {
+				if reflect.TypeOf(target).Kind() == reflect.Func || strings.Contains(command, ".") {
+					commands = append(commands, command)
+				}
+			}
+		}
+	case *constructor:
+		commands = targetCommands(reflect.New(t.structType).Interface())
+	default:
+		targetType := reflect.TypeOf(targets)
+		switch {
+		case targetType.Kind() == reflect.Func:
+			commands = append(commands, functionName(targets))
+		case targetType.Kind() == reflect.Struct || (targetType.Kind() == reflect.Ptr && targetType.Elem().Kind() == reflect.Struct):
+			structType := targetType
+			if structType.Kind() == reflect.Struct {
+				// include the pointer receiver methods which are callable once the target is made addressable
+				structType = reflect.PtrTo(structType)
+			}
+			for x := 0; x < structType.NumMethod(); x++ {
+				methodName := structType.Method(x).Name
+				commands = append(commands, methodName, structType.Elem().Name()+"."+methodName)
+			}
+		}
+	}
+
+	sort.Strings(commands)
+	return commands
+}
+
+// targetFieldFlags lists the --<field>= flags that can be used to set the struct attributes within the targets
+func targetFieldFlags(targets interface{}) []string {
+	var flags []string
+
+	switch t := targets.(type) {
+	case []interface{}:
+		for _, target := range t {
+			flags = append(flags, targetFieldFlags(target)...)
+		}
+	case *constructor:
+		walkAttributes(t.paramsType, func(name string, field reflect.StructField, nested bool) {
+			flags = append(flags, "--"+name+"=")
+		})
+		flags = append(flags, targetFieldFlags(reflect.New(t.structType).Interface())...)
+	default:
+		structType := reflect.TypeOf(targets)
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+		if structType.Kind() != reflect.Struct {
+			return nil
+		}
+
+		walkAttributes(structType, func(name string, field reflect.StructField, nested bool) {
+			flags = append(flags, "--"+name+"=")
+		})
+	}
+
+	sort.Strings(flags)
+	return flags
+}
+
+// historyFilePath is the file the interactive history is kept in, ie. ~/.<program>_history
+func historyFilePath(programName string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, "."+filepath.Base(programName)+"_history")
+}
@@ -0,0 +1,121 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestREPLExecute(t *testing.T) {
+	var out bytes.Buffer
+	session := newREPL("fuego", []interface{}{AddInt, MyMath{Offset: 1}}, &options{}, &out)
+
+	lines := []string{
+		"MyMath.Add 1 2",
+		"MyMath.Add 1 2 --Offset=10",
+		"MyMath.Add 1 2 # the offset is kept between commands",
+		"AddInt $1 3",
+		"",
+		"AddInt 1 2 --format='[{{.}}]'",
+		"AddInt $5 1",
+	}
+	for _, line := range lines {
+		if err := session.execute(line); err != nil {
+			t.Fatalf("Error is not expected for %q but got %v", line, err)
+		}
+	}
+
+	expected := "$1 = 4\n$2 = 13\n$3 = 13\n$4 = 7\n[3]\n$6 = 4\n"
+	if out.String() != expected {
+		t.Errorf("expected the session output %q but got %q", expected, out.String())
+	}
+
+	if err := session.execute("AddInt $9 1"); err == nil || !doErrorsMatch(errors.New(UnknownVariableError), err) {
+		t.Errorf("expected an unknown variable error but got %v", err)
+	}
+	if err := session.execute("MyMath.Divide 1 2"); err == nil || !doErrorsMatch(errors.New(MethodDoesNotExistError), err) {
+		t.Errorf("expected a method does not exist error but got %v", err)
+	}
+	if err := session.execute("quit"); err != errExitInteractive {
+		t.Errorf("expected quit to exit the session but got %v", err)
+	}
+}
+
+func TestREPLExecuteStream(t *testing.T) {
+	var out bytes.Buffer
+	session := newREPL("fuego", []interface{}{Countdown, &Journal{}}, &options{}, &out)
+
+	for _, line := range []string{"Countdown 2", "Journal.Entries"} {
+		if err := session.execute(line); err != nil {
+			t.Fatalf("Error is not expected for %q but got %v", line, err)
+		}
+	}
+
+	// the streams are drained rather than stored as variables, closing the target once drained
+	if expected := "2\n1\ncreated\nupdated\n"; out.String() != expected {
+		t.Errorf("expected the session output %q but got %q", expected, out.String())
+	}
+}
+
+func TestREPLComplete(t *testing.T) {
+	session := newREPL("fuego", []interface{}{AddInt, SubtractInt, MyMath{}}, &options{}, &bytes.Buffer{})
+
+	testCases := []struct {
+		Line     string
+		Expected []string
+		Length   int
+	}{
+		{"MyMath.S", []string{"ubtract"}, 8},
+		{"Ad", []string{"dInt"}, 2},
+		{"MyMath.Add 1 --Off", []string{"set="}, 5},
+		{"MyMath.Add 1", nil, 1},
+	}
+
+	for _, testCase := range testCases {
+		completions, length := session.Do([]rune(testCase.Line), len(testCase.Line))
+
+		var got []string
+		for _, completion := range completions {
+			got = append(got, string(completion))
+		}
+		if !reflect.DeepEqual(got, testCase.Expected) || length != testCase.Length {
+			t.Errorf("expected the completions %v (%d) for %q but got %v (%d)", testCase.Expected, testCase.Length, testCase.Line, got, length)
+		}
+	}
+}
+
+func TestSplitCommandLine(t *testing.T) {
+	testCases := []struct {
+		Line          string
+		Expected      []string
+		ExpectedError error
+	}{
+		{"Add 1 2", []string{"Add", "1", "2"}, nil},
+		{"  Greet 'hello world'   \"it's \\\"me\\\"\" ", []string{"Greet", "hello world", `it's "me"`}, nil},
+		{`Greet a\ b --Name=""`, []string{"Greet", "a b", "--Name="}, nil},
+		{"# a comment", nil, nil},
+		{"Add 1 2 # trailing comment", []string{"Add", "1", "2"}, nil},
+		{"Greet a#b", []string{"Greet", "a#b"}, nil},
+		{"Greet 'hi", nil, errors.Errorf(UnterminatedQuoteError, "Greet 'hi", "single")},
+		{`Greet "hi`, nil, errors.Errorf(UnterminatedQuoteError, `Greet "hi`, "double")},
+		{`Greet \`, nil, errors.New(TrailingBackslashError)},
+	}
+
+	for _, testCase := range testCases {
+		args, err := splitCommandLine(testCase.Line)
+		if testCase.ExpectedError != nil {
+			if err == nil || !doErrorsMatch(testCase.ExpectedError, err) {
+				t.Errorf("expected the error %v for %q but got %v", testCase.ExpectedError, testCase.Line, err)
+			}
+		} else if err != nil || !reflect.DeepEqual(args, testCase.Expected) {
+			t.Errorf("expected %q for %q but got %q (%v)", testCase.Expected, testCase.Line, args, err)
+		}
+	}
+}
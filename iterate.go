@@ -0,0 +1,280 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	StreamInterruptedError = "the returned stream was interrupted before it was drained"
+)
+
+// isChanResult reports whether the returned type is a channel that can be received from, ie. <-chan T
+func isChanResult(t reflect.Type) bool {
+	return t.Kind() == reflect.Chan && t.ChanDir()&reflect.RecvDir != 0
+}
+
+// isIteratorResult reports whether the returned type is a range over func iterator, ie. func(yield func(T) bool) or func(yield func(K, V) bool)
+func isIteratorResult(t reflect.Type) bool {
+	if t.Kind() != reflect.Func || t.NumIn() != 1 || t.NumOut() != 0 {
+		return false
+	}
+
+	yield := t.In(0)
+	return yield.Kind() == reflect.Func && (yield.NumIn() == 1 || yield.NumIn() == 2) && yield.NumOut() == 1 && yield.Out(0).Kind() == reflect.Bool
+}
+
+// isStreamResult reports whether the returned value is a channel or iterator whose elements are printed as they are produced
+func isStreamResult(val reflect.Value) bool {
+	return val.IsValid() && (isChanResult(val.Type()) || isIteratorResult(val.Type())) && !val.IsNil()
+}
+
+// hasStreamResults reports whether any of the returned values is a channel or iterator
+func hasStreamResults(values []reflect.Value) bool {
+	for _, val := range values {
+		if isStreamResult(val) {
+			return true
+		}
+	}
+	return false
+}
+
+// streamRelease holds the cleanup of a call whose returned channels or iterators are still being drained
+type streamRelease struct {
+	drained sync.WaitGroup
+	// stop is closed by Release to stop forwarding the channels and to mark the iterators that were not called as drained
+	stop     chan struct{}
+	stopOnce sync.Once
+	// done is closed once the cleanup ran, err is the error it returned
+	done chan struct{}
+	err  error
+}
+
+// Release runs the cleanup of the channels and iterators returned by Fuego (cancelling the injected context.Context, closing the files opened for
+// io.Reader and io.Writer params and closing the target) when they will not be drained, ie. when PrintToStdOut is false and the caller stops reading early.
+// It waits for the cleanup and returns its error, values without undrained streams are ignored. Streams must not be read from once they are released
+func Release(values []reflect.Value) error {
+	var err error
+	for _, release := range streamReleases(values) {
+		release.stopStreams()
+		<-release.done
+		if release.err != nil && err == nil {
+			err = release.err
+		}
+	}
+	return err
+}
+
+// stopStreams stops the streams of the release, it is called by Release and once the streams are drained so the goroutines waiting on stop return
+func (r *streamRelease) stopStreams() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+}
+
+var (
+	// releases holds the release of every stream that is not drained yet, keyed by the stream value returned to the caller.
+	// reflect.Value equality compares the channel or the func made by reflect.MakeFunc, which are unique to the stream
+	releases      = map[reflect.Value]*streamRelease{}
+	releasesMutex sync.Mutex
+)
+
+// releaseCall runs the cleanup of a call (cancelling the injected context, closing the files opened for its params and closing the target) once it returns.
+// Targets returning channels or iterators usually produce their elements after they return (ie. a goroutine sending on the channel until ctx.Done()
+// or an iterator reading from the target), so the cleanup runs once the streams are drained instead and its error is returned by printStreams
+func releaseCall(ctx context.Context, values []reflect.Value, err error, cleanup func() error) ([]reflect.Value, error) {
+	if err != nil || !hasStreamResults(values) {
+		if cleanupErr := cleanup(); cleanupErr != nil && err == nil {
+			err = cleanupErr
+		}
+		return values, err
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	release := &streamRelease{stop: make(chan struct{}), done: make(chan struct{})}
+	released := make([]reflect.Value, len(values))
+	for x, val := range values {
+		switch {
+		case !isStreamResult(val):
+			released[x] = val
+			continue
+		case isChanResult(val.Type()):
+			release.drained.Add(1)
+			released[x] = forwardChan(ctx, val, &release.drained, release.stop)
+		default:
+			release.drained.Add(1)
+			released[x] = wrapIterator(val, &release.drained, release.stop)
+		}
+
+		releasesMutex.Lock()
+		releases[released[x]] = release
+		releasesMutex.Unlock()
+	}
+
+	go func() {
+		release.drained.Wait()
+		release.stopStreams()
+		release.err = cleanup()
+
+		releasesMutex.Lock()
+		for _, val := range released {
+			delete(releases, val)
+		}
+		releasesMutex.Unlock()
+		close(release.done)
+	}()
+	return released, nil
+}
+
+// streamReleases returns the releases of the streams within the values, which are looked up before the streams are drained as they are forgotten once released
+func streamReleases(values []reflect.Value) []*streamRelease {
+	releasesMutex.Lock()
+	defer releasesMutex.Unlock()
+
+	var found []*streamRelease
+	for _, val := range values {
+		if release, ok := releases[val]; ok {
+			found = append(found, release)
+		}
+	}
+	return found
+}
+
+// forwardChan forwards the elements of the channel to a new channel of the same type, marking the stream drained once the channel is closed,
+// the context is done or the stream is released
+func forwardChan(ctx context.Context, ch reflect.Value, drained *sync.WaitGroup, stop <-chan struct{}) reflect.Value {
+	forwarded := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, ch.Type().Elem()), 0)
+	go func() {
+		defer drained.Done()
+		defer forwarded.Close()
+
+		done := reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}
+		stopped := reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(stop)}
+		for {
+			chosen, elem, ok := reflect.Select([]reflect.SelectCase{{Dir: reflect.SelectRecv, Chan: ch}, done, stopped})
+			if chosen != 0 || !ok {
+				return
+			}
+			if chosen, _, _ := reflect.Select([]reflect.SelectCase{{Dir: reflect.SelectSend, Chan: forwarded, Send: elem}, done, stopped}); chosen != 0 {
+				return
+			}
+		}
+	}()
+	return forwarded.Convert(ch.Type())
+}
+
+// wrapIterator wraps the iterator so the stream is marked drained once the iterator returns, or once the stream is released without being called
+func wrapIterator(iterator reflect.Value, drained *sync.WaitGroup, stop <-chan struct{}) reflect.Value {
+	var once sync.Once
+	go func() {
+		<-stop
+		once.Do(drained.Done)
+	}()
+
+	return reflect.MakeFunc(iterator.Type(), func(args []reflect.Value) []reflect.Value {
+		defer once.Do(drained.Done)
+		return iterator.Call(args)
+	})
+}
+
+// printStreams prints the returned values one per line, draining channels and iterators so each element is printed as soon as it is produced.
+// Draining stops on SIGINT / SIGTERM or once the --timeout deadline passes, the streams left undrained are then released. The cleanup of the call is
+// waited for and its error returned
+func printStreams(w io.Writer, values []reflect.Value, opts *options) error {
+	ctx, cancel := opts.newContext()
+	defer cancel()
+
+	released := streamReleases(values)
+	var err error
+	for _, val := range values {
+		switch {
+		case !isStreamResult(val):
+			err = printElement(w, []reflect.Value{val}, opts)
+		case isChanResult(val.Type()):
+			err = drainChan(ctx, w, val, opts)
+		default:
+			err = drainIterator(ctx, w, val, opts)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	for _, release := range released {
+		if err != nil {
+			release.stopStreams()
+		}
+		<-release.done
+		if release.err != nil && err == nil {
+			err = release.err
+		}
+	}
+	return err
+}
+
+// drainChan prints every element received from the channel until it is closed
+func drainChan(ctx context.Context, w io.Writer, ch reflect.Value, opts *options) error {
+	cases := []reflect.SelectCase{{Dir: reflect.SelectRecv, Chan: ch}, {Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())}}
+	for {
+		chosen, elem, ok := reflect.Select(cases)
+		if chosen == 1 {
+			return errors.Wrap(ctx.Err(), StreamInterruptedError)
+		} else if !ok {
+			return nil
+		}
+
+		if err := printElement(w, []reflect.Value{elem}, opts); err != nil {
+			return err
+		}
+	}
+}
+
+// drainIterator prints every element yielded by the iterator, pairs yielded by func(yield func(K, V) bool) iterators are printed like multiple returned values
+func drainIterator(ctx context.Context, w io.Writer, iterator reflect.Value, opts *options) error {
+	var printErr error
+	yield := reflect.MakeFunc(iterator.Type().In(0), func(elem []reflect.Value) []reflect.Value {
+		if ctx.Err() == nil {
+			printErr = printElement(w, elem, opts)
+		}
+		return []reflect.Value{reflect.ValueOf(ctx.Err() == nil && printErr == nil)}
+	})
+
+	// the iterator runs the code of the target so its panics are recovered like those of the call
+	if _, err := callTarget("iterator", iterator, []reflect.Value{yield}); err != nil {
+		return err
+	} else if printErr != nil {
+		return printErr
+	} else if ctx.Err() != nil {
+		return errors.Wrap(ctx.Err(), StreamInterruptedError)
+	}
+	return nil
+}
+
+// printElement prints a single element of a stream on its own line, applying the --format template when set
+func printElement(w io.Writer, elem []reflect.Value, opts *options) error {
+	if opts.format != nil {
+		return formatValues(w, opts.format, elem)
+	}
+
+	for x, val := range elem {
+		fmt.Fprint(w, val.Interface())
+		if x < len(elem)-1 {
+			fmt.Fprint(w, ", ")
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
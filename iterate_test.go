@@ -0,0 +1,179 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func Countdown(n int) <-chan int {
+	ch := make(chan int)
+	go func() {
+		defer close(ch)
+		for x := n; x > 0; x-- {
+			ch <- x
+		}
+	}()
+	return ch
+}
+
+func Words(ctx context.Context, sentence string) <-chan string {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for _, word := range strings.Fields(sentence) {
+			select {
+			case ch <- word:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+func Squares(n int) func(yield func(int) bool) {
+	return func(yield func(int) bool) {
+		for x := 0; x < n; x++ {
+			if !yield(x * x) {
+				return
+			}
+		}
+	}
+}
+
+func Enumerate(sentence string) func(yield func(int, string) bool) {
+	return func(yield func(int, string) bool) {
+		for x, word := range strings.Fields(sentence) {
+			if !yield(x, word) {
+				return
+			}
+		}
+	}
+}
+
+func Forever() func(yield func(int) bool) {
+	return func(yield func(int) bool) {
+		for x := 0; yield(x); x++ {
+		}
+	}
+}
+
+type Journal struct {
+	FailClose bool
+	closed    bool
+}
+
+func (j *Journal) Entries() func(yield func(string) bool) {
+	return func(yield func(string) bool) {
+		for _, entry := range []string{"created", "updated"} {
+			if j.closed {
+				entry = "closed"
+			}
+			if !yield(entry) {
+				return
+			}
+		}
+	}
+}
+
+func (j *Journal) Close() error {
+	j.closed = true
+	if j.FailClose {
+		return errors.New("the journal is locked")
+	}
+	return nil
+}
+
+func TestRelease(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	os.Args = []string{"fuego", "the quick fox"}
+	values, err := Fuego(Words)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Release(values); err != nil {
+		t.Errorf("Error is not expected but got %v", err)
+	}
+	// the context is cancelled so the channel is closed without being drained
+	for range values[0].Interface().(<-chan string) {
+	}
+
+	journal := &Journal{FailClose: true}
+	os.Args = []string{"fuego", "Entries"}
+	if values, err = Fuego(journal); err != nil {
+		t.Fatal(err)
+	}
+	if journal.closed {
+		t.Error("expected the target to stay open until the stream is released")
+	}
+	if err := Release(values); err == nil || !doErrorsMatch(errors.Wrapf(errors.New("the journal is locked"), CloseFailedError, "Journal"), err) {
+		t.Errorf("expected the close error to be returned but got %v", err)
+	}
+	if !journal.closed {
+		t.Error("expected the target to be closed once the stream is released")
+	}
+}
+
+func TestPrintStreams(t *testing.T) {
+	PrintToStdErr = false
+
+	testCases := []struct {
+		Name           string
+		Targets        interface{}
+		Args           []string
+		ExpectedOutput string
+		ExpectedError  error
+	}{
+		{"Chan", Countdown, []string{"fuego", "3"}, "3\n2\n1\n", nil},
+		{"ChanWithContext", Words, []string{"fuego", "the quick fox"}, "the\nquick\nfox\n", nil},
+		{"Iterator", Squares, []string{"fuego", "4"}, "0\n1\n4\n9\n", nil},
+		{"PairIterator", Enumerate, []string{"fuego", "a b"}, "0, a\n1, b\n", nil},
+		{"Format", Countdown, []string{"fuego", "2", "--format=#{{.}}"}, "#2\n#1\n", nil},
+		{"Timeout", Forever, []string{"fuego", "--timeout=20ms"}, "", errors.Wrap(context.DeadlineExceeded, StreamInterruptedError)},
+		{"ClosedAfterDrained", &Journal{}, []string{"fuego", "Entries"}, "created\nupdated\n", nil},
+		{"CloseFailedAfterDrained", &Journal{}, []string{"fuego", "Entries", "--FailClose"}, "", errors.Wrapf(errors.New("the journal is locked"), CloseFailedError, "Journal")},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			PrintToStdOut = true
+			defer func() { PrintToStdOut = false }()
+
+			opts := &options{}
+			args, err := opts.parse(testCase.Args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			values, err := dispatch(testCase.Targets, args, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var out bytes.Buffer
+			err = printValues(&out, values, opts)
+			if testCase.ExpectedError != nil {
+				if err == nil || !doErrorsMatch(testCase.ExpectedError, err) {
+					t.Errorf("Expected to receive the first error but instead the second error was returned: \n\t1) \"%v\"\n\t2) \"%v\"", testCase.ExpectedError, err)
+				}
+				return
+			} else if err != nil {
+				t.Errorf("Error is not expected but got %v", err)
+			}
+			if out.String() != testCase.ExpectedOutput {
+				t.Errorf("expected the output %q but got %q", testCase.ExpectedOutput, out.String())
+			}
+		})
+	}
+}
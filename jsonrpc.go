@@ -0,0 +1,193 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	InvalidJSONRPCRequestError = "the request is not a valid JSON-RPC 2.0 request"
+	JSONRPCMethodNotFoundError = "the method \"%v\" does not exist"
+)
+
+// the JSON-RPC 2.0 error codes, codes from -32000 to -32099 are reserved for implementation defined errors
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+	rpcTargetError    = -32000
+)
+
+// rpcRequest is a single JSON-RPC 2.0 request, requests without an id are notifications which are not responded to
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// rpcResult is the JSON-RPC 2.0 response for a successful call
+type rpcResult struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  interface{}     `json:"result"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// rpcErrorResponse is the JSON-RPC 2.0 response for a failed call
+type rpcErrorResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Error   rpcError        `json:"error"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// rpcError is the error object of a failed JSON-RPC 2.0 call
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// runJSONRPC serves JSON-RPC 2.0 requests read from std in, writing the responses to std out
+func runJSONRPC(targets interface{}, opts *options) error {
+	return serveJSONRPC(targets, opts, os.Stdin, os.Stdout)
+}
+
+// serveJSONRPC reads JSON-RPC 2.0 requests (and batches of requests) until the input is closed, dispatching every call against a single shared instance of the targets.
+// The method name follows the same dispatch rules as the first command line argument and the params are either a positional array or a named object.
+func serveJSONRPC(targets interface{}, opts *options, in io.Reader, out io.Writer) error {
+	// the params of requests are never read from files or std in, which also carries the requests
+	rpcOpts := *opts
+	rpcOpts.localArgs = false
+	opts = &rpcOpts
+
+	targets = addressableTargets(targets)
+	commands := listCommands(targets)
+
+	decoder := json.NewDecoder(in)
+	encoder := json.NewEncoder(out)
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err == io.EOF {
+			return nil
+		} else if err != nil {
+			// the stream can not be resynchronized after invalid JSON so the error is reported and the server stops
+			_ = encoder.Encode(newRPCError(nil, rpcParseError, err))
+			return err
+		}
+
+		var response interface{}
+		if raw = bytes.TrimSpace(raw); len(raw) > 0 && raw[0] == '[' {
+			var batch []json.RawMessage
+			if err := json.Unmarshal(raw, &batch); err != nil || len(batch) == 0 {
+				response = newRPCError(nil, rpcInvalidRequest, errors.New(InvalidJSONRPCRequestError))
+			} else {
+				var responses []interface{}
+				for _, request := range batch {
+					if resp := handleJSONRPC(targets, commands, opts, request); resp != nil {
+						responses = append(responses, resp)
+					}
+				}
+				if len(responses) > 0 {
+					response = responses
+				}
+			}
+		} else {
+			response = handleJSONRPC(targets, commands, opts, raw)
+		}
+
+		if response != nil {
+			if err := encoder.Encode(response); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// handleJSONRPC calls the target for a single request, returning the response or nil for notifications
+func handleJSONRPC(targets interface{}, commands []command, opts *options, raw json.RawMessage) interface{} {
+	var request rpcRequest
+	if err := json.Unmarshal(raw, &request); err != nil || request.JSONRPC != "2.0" || request.Method == "" {
+		return newRPCError(nil, rpcInvalidRequest, errors.New(InvalidJSONRPCRequestError))
+	}
+
+	response := callJSONRPC(targets, commands, opts, request)
+	if request.ID == nil {
+		return nil
+	}
+	return response
+}
+
+// callJSONRPC dispatches the request to the target and creates the response
+func callJSONRPC(targets interface{}, commands []command, opts *options, request rpcRequest) interface{} {
+	cmd, ok := findCommand(targets, commands, request.Method)
+	if !ok {
+		return newRPCError(request.ID, rpcMethodNotFound, errors.Errorf(JSONRPCMethodNotFoundError, request.Method))
+	}
+
+	params := request.Params
+	if bytes.Equal(bytes.TrimSpace(params), []byte("null")) {
+		params = nil
+	}
+	args, err := commandArgs(cmd, params)
+	if err != nil {
+		return newRPCError(request.ID, rpcInvalidParams, err)
+	}
+
+	values, err := dispatch(targets, append([]string{os.Args[0], cmd.Name}, args...), opts)
+	var hookErr *HookError
+	if isPanicError(err) {
+		return newRPCError(request.ID, rpcInternalError, err)
+	} else if errors.As(err, &hookErr) {
+		return newRPCError(request.ID, rpcTargetError, err)
+	} else if err != nil {
+		return newRPCError(request.ID, rpcInvalidParams, err)
+	}
+
+	results, err := targetResults(values)
+	if err != nil {
+		return newRPCError(request.ID, rpcTargetError, err)
+	}
+
+	response := rpcResult{JSONRPC: "2.0", ID: request.ID}
+	if len(results) == 1 {
+		response.Result = results[0]
+	} else if len(results) > 1 {
+		response.Result = results
+	}
+	return response
+}
+
+// findCommand finds the command called by name following the same rules as the first command line argument,
+// ie. "MyMath.Add" and "add" for a single struct target or "SubtractInt" and "subtractInt" for functions
+func findCommand(targets interface{}, commands []command, name string) (command, bool) {
+	_, isSlice := targets.([]interface{})
+	names := []string{name, strings.Title(name)}
+
+	for _, cmd := range commands {
+		for _, n := range names {
+			if cmd.Name == n || (!isSlice && cmd.Struct != nil && cmd.Name == cmd.Struct.Name()+"."+n) {
+				return cmd, true
+			}
+		}
+	}
+	return command{}, false
+}
+
+// newRPCError creates the JSON-RPC 2.0 error response, using a null id when the id of the request is not known
+func newRPCError(id json.RawMessage, code int, err error) rpcErrorResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return rpcErrorResponse{JSONRPC: "2.0", Error: rpcError{Code: code, Message: err.Error()}, ID: id}
+}
@@ -0,0 +1,50 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestServeJSONRPC(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Targets  interface{}
+		Request  string
+		Expected string
+	}{
+		{"PositionalParams", []interface{}{AddInt, &MyMath{}}, `{"jsonrpc":"2.0","method":"MyMath.Add","params":[5,3],"id":1}`, `{"jsonrpc":"2.0","result":8,"id":1}`},
+		{"NamedParams", []interface{}{AddInt}, `{"jsonrpc":"2.0","method":"addInt","params":{"a":1,"b":2},"id":"a"}`, `{"jsonrpc":"2.0","result":3,"id":"a"}`},
+		{"SingleStructMethod", MyMath{Offset: 1}, `{"jsonrpc":"2.0","method":"Add","params":[1,1],"id":2}`, `{"jsonrpc":"2.0","result":3,"id":2}`},
+		{"TrailingNilError", []interface{}{SubtractInt, CheckPositive, DivideInt}, `{"jsonrpc":"2.0","method":"CheckPositive","params":[3],"id":3}`, `{"jsonrpc":"2.0","result":3,"id":3}`},
+		{"MultipleResults", math.Frexp, `{"jsonrpc":"2.0","method":"Frexp","params":[-5],"id":4}`, `{"jsonrpc":"2.0","result":[-0.625,3],"id":4}`},
+		{"SharedInstance", MyMath{}, `{"jsonrpc":"2.0","method":"Add","params":{"a":1,"b":1,"Offset":5},"id":1}` + "\n" + `{"jsonrpc":"2.0","method":"Add","params":[1,1],"id":2}`, `{"jsonrpc":"2.0","result":7,"id":1}` + "\n" + `{"jsonrpc":"2.0","result":7,"id":2}`},
+		{"Notification", AddInt, `{"jsonrpc":"2.0","method":"AddInt","params":[1,2]}`, ``},
+		{"Batch", []interface{}{AddInt, SubtractInt}, `[{"jsonrpc":"2.0","method":"AddInt","params":[1,2],"id":1},{"jsonrpc":"2.0","method":"SubtractInt","params":[1,2]},{"jsonrpc":"2.0","method":"SubtractInt","params":[1,2],"id":2}]`, `[{"jsonrpc":"2.0","result":3,"id":1},{"jsonrpc":"2.0","result":-1,"id":2}]`},
+		{"MethodNotFound", AddInt, `{"jsonrpc":"2.0","method":"Nope","id":1}`, `{"jsonrpc":"2.0","error":{"code":-32601,"message":"the method \"Nope\" does not exist"},"id":1}`},
+		{"InvalidParams", AddInt, `{"jsonrpc":"2.0","method":"AddInt","params":[1],"id":1}`, `{"jsonrpc":"2.0","error":{"code":-32602,"message":"not enough arguments were passed in to setup the function parameter values"},"id":1}`},
+		{"TargetError", CheckPositive, `{"jsonrpc":"2.0","method":"CheckPositive","params":[-1],"id":1}`, `{"jsonrpc":"2.0","error":{"code":-32000,"message":"the number is negative"},"id":1}`},
+		{"Panic", DivideInt, `{"jsonrpc":"2.0","method":"DivideInt","params":[1,0],"id":1}`, `{"jsonrpc":"2.0","error":{"code":-32603,"message":"the call to \"DivideInt\" panicked: runtime error: integer divide by zero"},"id":1}`},
+		{"InvalidRequest", AddInt, `{"method":"AddInt","id":1}`, `{"jsonrpc":"2.0","error":{"code":-32600,"message":"the request is not a valid JSON-RPC 2.0 request"},"id":null}`},
+		{"ArgsNotExpanded", Echo, `{"jsonrpc":"2.0","method":"Echo","params":["@/etc/passwd"],"id":1}` + "\n" + `{"jsonrpc":"2.0","method":"Echo","params":["-"],"id":2}`, `{"jsonrpc":"2.0","result":"@/etc/passwd","id":1}` + "\n" + `{"jsonrpc":"2.0","result":"-","id":2}`},
+		{"ParseError", AddInt, `{"jsonrpc"`, `{"jsonrpc":"2.0","error":{"code":-32700,"message":"unexpected EOF"},"id":null}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var out bytes.Buffer
+			// the options of the command line read "@<path>" and "-" args locally, which the requests must not be able to do
+			_ = serveJSONRPC(testCase.Targets, &options{localArgs: true}, strings.NewReader(testCase.Request), &out)
+
+			if strings.TrimSpace(out.String()) != testCase.Expected {
+				t.Errorf("expected the response %s but got %s", testCase.Expected, out.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+const (
+	ValidateFailedError = "the attributes of struct \"%v\" are not valid"
+	InitFailedError     = "the struct \"%v\" could not be initialized"
+	CloseFailedError    = "the struct \"%v\" could not be closed"
+)
+
+// HookError is returned when a lifecycle hook (Validate, Init or Close) or the constructor of a struct target fails,
+// so callers (ie. the http server) can tell a failing target apart from args that could not be converted
+type HookError struct {
+	// Target is the name of the struct, or of the constructor when Hook is "Constructor"
+	Target string
+	// Hook is the hook that failed, ie. "Validate", "Init", "Close" or "Constructor"
+	Hook string
+	// Err is the error returned by the hook, nil when a constructor returned a nil instance
+	Err error
+
+	message string
+}
+
+// newHookError creates the error of the failed hook, the message is formatted with the target like the other fuego errors
+func newHookError(target string, hook string, err error, message string) *HookError {
+	return &HookError{Target: target, Hook: hook, Err: err, message: fmt.Sprintf(message, target)}
+}
+
+func (e *HookError) Error() string {
+	if e.Err == nil {
+		return e.message
+	}
+	return e.message + ": " + e.Err.Error()
+}
+
+// Unwrap returns the error returned by the hook
+func (e *HookError) Unwrap() error {
+	return e.Err
+}
+
+// validator is implemented by struct targets that check their attributes once the flags are applied, before anything is initialized
+type validator interface {
+	Validate() error
+}
+
+// initializer is implemented by struct targets that set up resources (ie. a database connection) right before the method is called
+type initializer interface {
+	Init() error
+}
+
+// validateTarget calls Validate on the target when it is implemented
+func validateTarget(targetVal reflect.Value, structName string) error {
+	if _, ok := targetVal.Interface().(validator); !ok {
+		return nil
+	}
+	return callHook(targetVal, structName, "Validate", ValidateFailedError)
+}
+
+// initTarget calls Init on the target when it is implemented
+func initTarget(targetVal reflect.Value, structName string) error {
+	if _, ok := targetVal.Interface().(initializer); !ok {
+		return nil
+	}
+	return callHook(targetVal, structName, "Init", InitFailedError)
+}
+
+// closeTarget calls Close on the target when it implements io.Closer, it is called after the method even when the method (or Init) failed
+func closeTarget(targetVal reflect.Value, structName string) error {
+	if _, ok := targetVal.Interface().(io.Closer); !ok {
+		return nil
+	}
+	return callHook(targetVal, structName, "Close", CloseFailedError)
+}
+
+// callHook calls the lifecycle method of the target, a returned error is wrapped with the message while a panic is reported like the panic of any other call
+func callHook(targetVal reflect.Value, structName string, methodName string, message string) error {
+	values, err := callTarget(structName+"."+methodName, targetVal.MethodByName(methodName), nil)
+	if err != nil {
+		return err
+	}
+	if hookErr := values[0]; !hookErr.IsNil() {
+		return newHookError(structName, methodName, hookErr.Interface().(error), message)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type Store struct {
+	Path      string
+	FailInit  bool
+	FailClose bool
+	events    []string
+}
+
+func (s *Store) Validate() error {
+	s.events = append(s.events, "validate")
+	if s.Path == "" {
+		return errors.New("a path is required")
+	}
+	return nil
+}
+
+func (s *Store) Init() error {
+	s.events = append(s.events, "init")
+	if s.FailInit {
+		return errors.New("the store is locked")
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	s.events = append(s.events, "close")
+	if s.FailClose {
+		return errors.New("the store is busy")
+	}
+	return nil
+}
+
+func (s *Store) Get(key string) string {
+	s.events = append(s.events, "get")
+	return s.Path + "/" + key
+}
+
+func (s *Store) Corrupt() {
+	s.events = append(s.events, "corrupt")
+	panic("the store is corrupt")
+}
+
+func TestLifecycle(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	testCases := []struct {
+		Name           string
+		Args           []string
+		ExpectedEvents []string
+		ExpectedError  error
+	}{
+		{"Success", []string{"Fuego.Lifecycle.Success", "Get", "a", "--Path=/tmp"}, []string{"validate", "init", "get", "close"}, nil},
+		{"ValidateFailed", []string{"Fuego.Lifecycle.ValidateFailed", "Get", "a"}, []string{"validate"}, errors.Wrapf(errors.New("a path is required"), ValidateFailedError, "Store")},
+		{"InitFailed", []string{"Fuego.Lifecycle.InitFailed", "Get", "a", "--Path=/tmp", "--FailInit"}, []string{"validate", "init", "close"}, errors.Wrapf(errors.New("the store is locked"), InitFailedError, "Store")},
+		{"CloseFailed", []string{"Fuego.Lifecycle.CloseFailed", "Get", "a", "--Path=/tmp", "--FailClose"}, []string{"validate", "init", "get", "close"}, errors.Wrapf(errors.New("the store is busy"), CloseFailedError, "Store")},
+		{"ClosedAfterPanic", []string{"Fuego.Lifecycle.ClosedAfterPanic", "Corrupt", "--Path=/tmp", "--FailClose"}, []string{"validate", "init", "corrupt", "close"}, errors.Errorf(TargetPanicError, "Store.Corrupt", "the store is corrupt")},
+		{"NotInitializedForInvalidArgs", []string{"Fuego.Lifecycle.NotInitializedForInvalidArgs", "Get", "--Path=/tmp"}, []string{"validate"}, errors.New(InsufficientArgumentsError)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			store := &Store{}
+			os.Args = testCase.Args
+			_, err := Fuego(store)
+
+			if testCase.ExpectedError != nil {
+				if err == nil || !doErrorsMatch(testCase.ExpectedError, err) {
+					t.Errorf("Expected to receive the first error but instead the second error was returned: \n\t1) \"%v\"\n\t2) \"%v\"", testCase.ExpectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("Error is not expected but got %v", err)
+			}
+
+			if !reflect.DeepEqual(store.events, testCase.ExpectedEvents) {
+				t.Errorf("expected the lifecycle %v but got %v", testCase.ExpectedEvents, store.events)
+			}
+		})
+	}
+}
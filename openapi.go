@@ -0,0 +1,304 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// openAPIPath is the path the http server serves the OpenAPI document at
+const openAPIPath = "/openapi.json"
+
+// OpenAPI returns the OpenAPI 3 document describing the http endpoints served for the targets by Handler() or --serve
+func OpenAPI(targets interface{}) map[string]interface{} {
+	paths := map[string]interface{}{}
+	for _, cmd := range listCommands(targets) {
+		paths["/"+strings.Replace(cmd.Name, ".", "/", -1)] = map[string]interface{}{
+			"post": openAPIOperation(cmd),
+		}
+	}
+
+	return map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":   filepath.Base(os.Args[0]),
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": map[string]interface{}{
+				"Error": map[string]interface{}{
+					"type":     "object",
+					"required": []string{"error"},
+					"properties": map[string]interface{}{
+						"error": map[string]interface{}{
+							"type":     "object",
+							"required": []string{"type", "message"},
+							"properties": map[string]interface{}{
+								"type": map[string]interface{}{
+									"type": "string",
+									"enum": []string{requestErrorType, notFoundErrorType, argumentsErrorType, panicErrorType, targetErrorType},
+								},
+								"message": map[string]interface{}{"type": "string"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// writeOpenAPI writes the OpenAPI document for the targets as indented JSON, used by --openapi
+func writeOpenAPI(w io.Writer, targets interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(OpenAPI(targets))
+}
+
+// serveOpenAPI writes the OpenAPI document for the targets as the http response
+func serveOpenAPI(w http.ResponseWriter, targets interface{}) {
+	writeServerJSON(w, http.StatusOK, OpenAPI(targets))
+}
+
+// openAPIOperation describes the POST operation of a single function or struct method
+func openAPIOperation(cmd command) map[string]interface{} {
+	_, paramTypes := cmd.Params()
+
+	// the body is either an object of named parameters (and struct attributes) or an array of positional parameters
+	positional := make([]interface{}, len(paramTypes))
+	for x, paramType := range paramTypes {
+		positional[x] = jsonSchema(paramType)
+	}
+
+	var results []interface{}
+	for x := 0; x < cmd.Func.NumOut(); x++ {
+		if x == cmd.Func.NumOut()-1 && cmd.Func.Out(x) == errorType {
+			break
+		}
+		results = append(results, jsonSchema(cmd.Func.Out(x)))
+	}
+
+	errorResponse := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"description": description,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"},
+				},
+			},
+		}
+	}
+
+	// without the real parameter names only the array of positional parameters is accepted
+	bodySchema := tupleSchema(positional)
+	if cmd.HasParamNames() {
+		bodySchema = map[string]interface{}{"oneOf": []interface{}{namedArgsSchema(cmd), tupleSchema(positional)}}
+	}
+
+	operation := map[string]interface{}{
+		"operationId": cmd.Name,
+		"requestBody": map[string]interface{}{
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": bodySchema,
+				},
+			},
+		},
+		"responses": map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "the values returned by " + cmd.Name,
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": map[string]interface{}{
+							"type":       "object",
+							"required":   []string{"results"},
+							"properties": map[string]interface{}{"results": tupleSchema(results)},
+						},
+					},
+				},
+			},
+			"400": errorResponse("the arguments could not be converted to the parameters"),
+			"404": errorResponse("the function or method does not exist"),
+			"500": errorResponse("the call panicked or returned an error"),
+		},
+	}
+	if cmd.Struct != nil {
+		operation["tags"] = []string{cmd.Struct.Name()}
+	}
+	if cmd.Doc.Doc != "" {
+		operation["summary"] = strings.SplitN(cmd.Doc.Doc, "\n", 2)[0]
+		operation["description"] = cmd.Doc.Doc
+	}
+	return operation
+}
+
+// namedArgsSchema describes the object of named parameters (which are required) and struct attributes (which are optional) accepted by the command
+func namedArgsSchema(cmd command) map[string]interface{} {
+	paramNames, paramTypes := cmd.Params()
+
+	properties := map[string]interface{}{}
+	for x, paramType := range paramTypes {
+		properties[paramNames[x]] = jsonSchema(paramType)
+	}
+
+	// the attributes are described by the paths the server sets them by, ie. DB.Host or the promoted Region of an embedded struct, while
+	// the elements of slices and maps are matched by pattern, ie. Servers[0].Host or Labels[env]
+	patterns := map[string]interface{}{}
+	if cmd.Struct != nil {
+		walkAttributes(cmd.Struct, func(name string, field reflect.StructField, nested bool) {
+			if _, isParam := properties[name]; isParam {
+				return
+			}
+
+			elemType := indirectType(field.Type)
+			switch elemType.Kind() {
+			case reflect.Slice, reflect.Array:
+				name, elemType = name+"[<index>]", elemType.Elem()
+			case reflect.Map:
+				name, elemType = name+"[<key>]", elemType.Elem()
+			}
+
+			schema := jsonSchema(elemType)
+			tag := parseFuegoTag(field)
+			if tag.usage != "" {
+				schema["description"] = tag.usage
+			}
+			if isElementPath(name) {
+				patterns[attributePattern(name)] = schema
+				return
+			}
+			if vals, err := convertStringsToReflectValues([]reflect.Kind{field.Type.Kind()}, []string{tag.def}); tag.hasDefault && err == nil {
+				schema["default"] = vals[0].Interface()
+			}
+			properties[name] = schema
+		})
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(patterns) > 0 {
+		schema["patternProperties"] = patterns
+	}
+	if len(paramNames) > 0 {
+		schema["required"] = paramNames
+	}
+	return schema
+}
+
+// attributePattern converts the attribute path of slice or map elements into the regular expression of the names it stands for,
+// ie. ^Servers\[[0-9]+\]\.Host$ for Servers[<index>].Host
+func attributePattern(name string) string {
+	pattern := regexp.QuoteMeta(name)
+	pattern = strings.Replace(pattern, regexp.QuoteMeta("[<index>]"), `\[[0-9]+\]`, -1)
+	pattern = strings.Replace(pattern, regexp.QuoteMeta("[<key>]"), `\[[^\]]+\]`, -1)
+	return "^" + pattern + "$"
+}
+
+// tupleSchema describes a fixed length array where each position has its own schema
+func tupleSchema(items []interface{}) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":     "array",
+		"minItems": len(items),
+		"maxItems": len(items),
+		"items":    false,
+	}
+	if len(items) > 0 {
+		schema["prefixItems"] = items
+	}
+	return schema
+}
+
+// jsonSchema describes the go type as a JSON schema
+func jsonSchema(t reflect.Type) map[string]interface{} {
+	return jsonSchemaOf(t, map[reflect.Type]bool{})
+}
+
+// jsonSchemaOf describes the go type as a JSON schema, tracking the struct types being described so recursive types terminate
+func jsonSchemaOf(t reflect.Type, describing map[reflect.Type]bool) map[string]interface{} {
+	if t == errorType {
+		return map[string]interface{}{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]interface{}{"type": "integer", "format": jsonSchemaIntFormat(t)}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer", "format": jsonSchemaIntFormat(t), "minimum": 0}
+	case reflect.Float32:
+		return map[string]interface{}{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Ptr:
+		return jsonSchemaOf(t.Elem(), describing)
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes byte slices as base64 strings
+			return map[string]interface{}{"type": "string", "contentEncoding": "base64"}
+		}
+		return map[string]interface{}{"type": "array", "items": jsonSchemaOf(t.Elem(), describing)}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": jsonSchemaOf(t.Elem(), describing)}
+	case reflect.Struct:
+		if describing[t] {
+			return map[string]interface{}{"type": "object"}
+		}
+		describing[t] = true
+		defer delete(describing, t)
+
+		properties := map[string]interface{}{}
+		for x := 0; x < t.NumField(); x++ {
+			field := t.Field(x)
+			name := jsonFieldName(field)
+			if name == "" {
+				continue
+			}
+			properties[name] = jsonSchemaOf(field.Type, describing)
+		}
+		return map[string]interface{}{"type": "object", "properties": properties}
+	default:
+		// interfaces, channels and functions can not be described
+		return map[string]interface{}{}
+	}
+}
+
+// jsonSchemaIntFormat returns the OpenAPI format of an integer type, which is either "int32" or "int64"
+func jsonSchemaIntFormat(t reflect.Type) string {
+	if t.Bits() <= 32 {
+		return "int32"
+	}
+	return "int64"
+}
+
+// jsonFieldName returns the name encoding/json uses for the struct field, or "" when the field is not encoded
+func jsonFieldName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		return ""
+	}
+
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	if name := strings.Split(tag, ",")[0]; name != "" {
+		return name
+	}
+	return field.Name
+}
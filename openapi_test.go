@@ -0,0 +1,116 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+type OpenAPINode struct {
+	Name     string
+	Weight   uint8 `json:"weight,omitempty"`
+	Children []*OpenAPINode
+	Data     []byte `json:"-"`
+}
+
+func (n *OpenAPINode) Find(name string) (*OpenAPINode, error) {
+	return nil, nil
+}
+
+func TestOpenAPI(t *testing.T) {
+	server := httptest.NewServer(Handler([]interface{}{AddInt, CheckPositive, &MyMath{}, OpenAPINode{}}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + openAPIPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var doc map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		t.Fatalf("the OpenAPI document is not valid JSON: %v", err)
+	}
+	if doc["openapi"] != "3.1.0" {
+		t.Errorf("expected an OpenAPI 3.1.0 document but got %v", doc["openapi"])
+	}
+
+	testCases := []struct {
+		Name     string
+		Path     []string
+		Expected interface{}
+	}{
+		{"FunctionParameters", []string{"paths", "/AddInt", "post", "requestBody", "content", "application/json", "schema", "oneOf", "0", "required"}, []interface{}{"a", "b"}},
+		{"FunctionParameterType", []string{"paths", "/AddInt", "post", "requestBody", "content", "application/json", "schema", "oneOf", "0", "properties", "a", "type"}, "integer"},
+		{"PositionalParameters", []string{"paths", "/AddInt", "post", "requestBody", "content", "application/json", "schema", "oneOf", "1", "maxItems"}, float64(2)},
+		{"StructAttribute", []string{"paths", "/MyMath/Add", "post", "requestBody", "content", "application/json", "schema", "oneOf", "0", "properties", "Offset", "format"}, "double"},
+		{"ContextParameterSkipped", []string{"paths", "/MyMath/Multiply", "post", "requestBody", "content", "application/json", "schema", "oneOf", "0", "required"}, []interface{}{"a", "b"}},
+		{"StructTag", []string{"paths", "/MyMath/Add", "post", "tags"}, []interface{}{"MyMath"}},
+		{"Results", []string{"paths", "/MyMath/Add", "post", "responses", "200", "content", "application/json", "schema", "properties", "results", "prefixItems", "0", "type"}, "number"},
+		{"TrailingErrorResult", []string{"paths", "/CheckPositive", "post", "responses", "200", "content", "application/json", "schema", "properties", "results", "maxItems"}, float64(1)},
+		{"ErrorResponse", []string{"paths", "/CheckPositive", "post", "responses", "500", "content", "application/json", "schema", "$ref"}, "#/components/schemas/Error"},
+		{"PointerMethodOnValueTarget", []string{"paths", "/OpenAPINode/Find", "post", "operationId"}, "OpenAPINode.Find"},
+		{"RecursiveResult", []string{"paths", "/OpenAPINode/Find", "post", "responses", "200", "content", "application/json", "schema", "properties", "results", "prefixItems", "0", "properties", "Children", "items"}, map[string]interface{}{"type": "object"}},
+		{"JSONTagName", []string{"paths", "/OpenAPINode/Find", "post", "responses", "200", "content", "application/json", "schema", "properties", "results", "prefixItems", "0", "properties", "weight", "minimum"}, float64(0)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var node interface{} = doc
+			for _, key := range testCase.Path {
+				switch n := node.(type) {
+				case map[string]interface{}:
+					node = n[key]
+				case []interface{}:
+					var index int
+					_ = json.Unmarshal([]byte(key), &index)
+					node = n[index]
+				default:
+					t.Fatalf("could not find %q within the OpenAPI document", key)
+				}
+			}
+
+			if !reflect.DeepEqual(node, testCase.Expected) {
+				t.Errorf("expected %v at %v but got %v", testCase.Expected, testCase.Path, node)
+			}
+		})
+	}
+}
+
+func TestNamedArgsSchemaAttributes(t *testing.T) {
+	cmd := listCommands(&Deployment{})[0]
+	schema := namedArgsSchema(cmd)
+
+	properties := schema["properties"].(map[string]interface{})
+	for _, name := range []string{"DB.Host", "DB.Port", "Primary.Host", "Region"} {
+		if _, ok := properties[name]; !ok {
+			t.Errorf("expected the attribute %q to be a property but got %v", name, properties)
+		}
+	}
+	for _, name := range []string{"DB", "Location", "Servers", "Labels", "Ports"} {
+		if _, ok := properties[name]; ok {
+			t.Errorf("expected the attribute %q that cannot be set as a whole to be left out but got %v", name, properties)
+		}
+	}
+
+	patterns := schema["patternProperties"].(map[string]interface{})
+	expected := map[string]string{`^Servers\[[0-9]+\]\.Host$`: "Servers[0].Host", `^Labels\[[^\]]+\]$`: "Labels[env]", `^Ports\[[0-9]+\]$`: "Ports[1]", `^Replicas\[[^\]]+\]\.Port$`: "Replicas[east].Port"}
+	for pattern, name := range expected {
+		if _, ok := patterns[pattern]; !ok {
+			t.Errorf("expected the pattern %q but got %v", pattern, patterns)
+		} else if matched, _ := regexp.MatchString(pattern, name); !matched {
+			t.Errorf("expected the pattern %q to match %q", pattern, name)
+		}
+		if _, ok := attributeType(cmd.Struct, name, false); !ok {
+			t.Errorf("expected the attribute %q described by the schema to be accepted", name)
+		}
+	}
+}
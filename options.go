@@ -0,0 +1,316 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	MissingOptionValueError = "the fuego option \"%v\" requires a value"
+	InvalidOptionValueError = "the value \"%v\" is not valid for the fuego option \"%v\""
+)
+
+// options holds the fuego specific flags (ie. --format) that are parsed out of the command line before the targets are dispatched
+type options struct {
+	format      *template.Template
+	timeout     time.Duration
+	debug       bool
+	interactive bool
+	script      string
+	stopOnError bool
+	serve       string
+	openAPI     bool
+	jsonRPC     bool
+	tools       bool
+	config      string
+	help        bool
+	trace       bool
+	timing      bool
+	repeat      int
+	cpuProfile  string
+	memProfile  string
+	each        bool
+	jobs        int
+	unordered   bool
+	// executionTrace is the file the runtime/trace execution trace is written to with --trace-out
+	executionTrace string
+
+	// baseContext is the parent of the context injected into targets, ie. the http request context when serving
+	baseContext context.Context
+	// middleware is wrapped around every call of a function or struct method, set by App.Use
+	middleware []Middleware
+	// diagOut is where --trace and --time are written, std err when not set
+	diagOut io.Writer
+	// localArgs is set when the args come from the local command line (including --script, --interactive and --args-file) rather than a remote caller,
+	// only then are "-" and "@<path>" args read from std in and files
+	localArgs bool
+	// stdin is where "-" args and --args-file=- are read from, std in when not set
+	stdin io.Reader
+	// stdout is where io.Writer parameters passed "-" write, std out when not set
+	stdout io.Writer
+	// release runs with the cleanup of a struct method call, ie. cancelling the context injected into the constructor of the target
+	release func()
+	// attributes are the attribute flags of the struct targets, an attribute named like a fuego option (ie. `fuego:"name=config"`) takes priority over the option
+	attributes map[string]bool
+}
+
+// parseOptions pulls the fuego specific flags out of the args, returning the parsed options and the remaining args to dispatch on
+func parseOptions(args []string) (*options, []string, error) {
+	opts := &options{}
+	remaining, err := opts.parse(args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return opts, remaining, nil
+}
+
+// parse pulls the fuego specific flags out of the args on top of the options already set, returning the remaining args to dispatch on
+func (opts *options) parse(args []string) ([]string, error) {
+	remaining := make([]string, 0, len(args))
+	// included guards against args files that include themselves
+	included := map[string]bool{}
+
+	for x := 0; x < len(args); x++ {
+		arg, start := args[x], x
+		if arg == "--" {
+			// every arg after the "--" terminator is passed through to the target untouched
+			remaining = append(remaining, args[x:]...)
+			break
+		} else if x == 0 || !strings.HasPrefix(arg, "--") {
+			remaining = append(remaining, arg)
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(arg[2:], "=")
+		if opts.attributes[name] {
+			remaining = append(remaining, arg)
+			continue
+		}
+		// optionValue returns the value of the current option, consuming the next arg when it was not passed as --<option>=<value>
+		optionValue := func() (string, error) {
+			if !hasValue {
+				if x+1 >= len(args) {
+					return "", errors.Errorf(MissingOptionValueError, name)
+				}
+				x++
+				value = args[x]
+			}
+			return value, nil
+		}
+
+		switch name {
+		case "format":
+			value, err := optionValue()
+			if err != nil {
+				return nil, err
+			}
+
+			tmpl, err := parseFormat(value)
+			if err != nil {
+				return nil, err
+			}
+			opts.format = tmpl
+		case "timeout":
+			value, err := optionValue()
+			if err != nil {
+				return nil, err
+			}
+
+			timeout, err := time.ParseDuration(value)
+			if err != nil || timeout <= 0 {
+				return nil, errors.Errorf(InvalidOptionValueError, value, name)
+			}
+			opts.timeout = timeout
+		case "debug":
+			debug, err := boolOptionValue(name, value, hasValue)
+			if err != nil {
+				return nil, err
+			}
+			opts.debug = debug
+		case "interactive":
+			interactive, err := boolOptionValue(name, value, hasValue)
+			if err != nil {
+				return nil, err
+			}
+			opts.interactive = interactive
+		case "script":
+			value, err := optionValue()
+			if err != nil {
+				return nil, err
+			}
+			opts.script = value
+		case "stop-on-error":
+			stopOnError, err := boolOptionValue(name, value, hasValue)
+			if err != nil {
+				return nil, err
+			}
+			opts.stopOnError = stopOnError
+		case "serve":
+			value, err := optionValue()
+			if err != nil {
+				return nil, err
+			}
+			opts.serve = value
+		case "openapi":
+			openAPI, err := boolOptionValue(name, value, hasValue)
+			if err != nil {
+				return nil, err
+			}
+			opts.openAPI = openAPI
+		case "jsonrpc":
+			jsonRPC, err := boolOptionValue(name, value, hasValue)
+			if err != nil {
+				return nil, err
+			}
+			opts.jsonRPC = jsonRPC
+		case "tools":
+			tools, err := boolOptionValue(name, value, hasValue)
+			if err != nil {
+				return nil, err
+			}
+			opts.tools = tools
+		case "config":
+			value, err := optionValue()
+			if err != nil {
+				return nil, err
+			}
+			opts.config = value
+		case "help":
+			help, err := boolOptionValue(name, value, hasValue)
+			if err != nil {
+				return nil, err
+			}
+			opts.help = help
+		case "trace":
+			trace, err := boolOptionValue(name, value, hasValue)
+			if err != nil {
+				return nil, err
+			}
+			opts.trace = trace
+		case "time":
+			timing, err := boolOptionValue(name, value, hasValue)
+			if err != nil {
+				return nil, err
+			}
+			opts.timing = timing
+		case "repeat":
+			value, err := optionValue()
+			if err != nil {
+				return nil, err
+			}
+
+			repeat, err := strconv.Atoi(value)
+			if err != nil || repeat < 1 {
+				return nil, errors.Errorf(InvalidOptionValueError, value, name)
+			}
+			opts.repeat = repeat
+		case "cpuprofile":
+			value, err := optionValue()
+			if err != nil {
+				return nil, err
+			}
+			opts.cpuProfile = value
+		case "memprofile":
+			value, err := optionValue()
+			if err != nil {
+				return nil, err
+			}
+			opts.memProfile = value
+		case "trace-out":
+			value, err := optionValue()
+			if err != nil {
+				return nil, err
+			}
+			opts.executionTrace = value
+		case "each":
+			each, err := boolOptionValue(name, value, hasValue)
+			if err != nil {
+				return nil, err
+			}
+			opts.each = each
+		case "jobs":
+			value, err := optionValue()
+			if err != nil {
+				return nil, err
+			}
+
+			jobs, err := strconv.Atoi(value)
+			if err != nil || jobs < 1 {
+				return nil, errors.Errorf(InvalidOptionValueError, value, name)
+			}
+			opts.jobs = jobs
+		case "unordered":
+			unordered, err := boolOptionValue(name, value, hasValue)
+			if err != nil {
+				return nil, err
+			}
+			opts.unordered = unordered
+		case "args-file":
+			value, err := optionValue()
+			if err != nil {
+				return nil, err
+			} else if included[value] {
+				return nil, errors.Errorf(ArgsFileRecursionError, value)
+			}
+			included[value] = true
+
+			fileArgs, err := opts.readArgsFile(value)
+			if err != nil {
+				return nil, err
+			}
+			// the args of the file take the place of the option so they are parsed as if they were passed in on the command line
+			args = append(append(append([]string{}, args[:start]...), fileArgs...), args[x+1:]...)
+			x = start - 1
+		default:
+			remaining = append(remaining, arg)
+		}
+	}
+
+	return remaining, nil
+}
+
+// attributeFlags returns the attribute flags of the struct and constructor targets, ie. DB.Host, so the fuego options can leave them to the targets
+func attributeFlags(targets interface{}) map[string]bool {
+	flags := map[string]bool{}
+	for _, target := range targetList(targets) {
+		structTypes := []reflect.Type{indirectType(reflect.TypeOf(target))}
+		if c, ok := target.(*constructor); ok {
+			structTypes = []reflect.Type{c.paramsType, c.structType}
+		}
+
+		for _, structType := range structTypes {
+			if structType.Kind() != reflect.Struct {
+				continue
+			}
+			walkAttributes(structType, func(name string, field reflect.StructField, nested bool) {
+				flags[name] = true
+			})
+		}
+	}
+	return flags
+}
+
+// boolOptionValue parses the value of a boolean fuego option, which is true when passed without a value (ie. --debug)
+func boolOptionValue(name string, value string, hasValue bool) (bool, error) {
+	if !hasValue {
+		return true, nil
+	}
+
+	val, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, errors.Errorf(InvalidOptionValueError, value, name)
+	}
+	return val, nil
+}
@@ -0,0 +1,42 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"fmt"
+	"reflect"
+	"runtime/debug"
+)
+
+const (
+	TargetPanicError = "the call to \"%v\" panicked: %v"
+)
+
+// PanicError is returned in place of a crash when the target (or reflect itself, ie. on mismatched arguments) panics during the call
+type PanicError struct {
+	// Target is the name of the function or method that was being called
+	Target string
+	// Value is the value that was passed to panic()
+	Value interface{}
+	// Stack is the stack trace captured when the panic was recovered
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf(TargetPanicError, e.Target, e.Value)
+}
+
+// callTarget reflectively calls the function with the params, recovering any panic into a *PanicError
+func callTarget(name string, fn reflect.Value, params []reflect.Value) (values []reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			values = nil
+			err = &PanicError{Target: name, Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return fn.Call(params), nil
+}
@@ -0,0 +1,70 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type Celsius float64
+
+func ToFahrenheit(c Celsius) float64 {
+	return float64(c)*9/5 + 32
+}
+
+func TestPanicRecovery(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	testCases := []struct {
+		Name          string
+		Targets       interface{}
+		Args          []string
+		ExpectedValue string
+	}{
+		{"TargetPanic", DivideInt, []string{"Fuego.TargetPanic", "7", "0"}, "integer divide by zero"},
+		{"ReflectCallPanic", ToFahrenheit, []string{"Fuego.ReflectCallPanic", "100"}, "reflect: Call using float64 as type fuego.Celsius"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			os.Args = testCase.Args
+			_, err := Fuego(testCase.Targets)
+
+			panicErr, ok := errors.Cause(err).(*PanicError)
+			if !ok {
+				t.Fatalf("expected a *PanicError to be returned but got %v", err)
+			}
+			if !strings.Contains(panicErr.Error(), testCase.ExpectedValue) {
+				t.Errorf("expected the panic error to contain %q but got %q", testCase.ExpectedValue, panicErr.Error())
+			}
+			if !strings.Contains(string(panicErr.Stack), "callTarget") {
+				t.Errorf("expected the stack trace of the panic to be captured but got %q", panicErr.Stack)
+			}
+		})
+	}
+}
+
+func TestPrintStack(t *testing.T) {
+	PrintToStdErr = true
+	defer func() { PrintToStdErr = false }()
+
+	panicErr := &PanicError{Target: "DivideInt", Value: "boom", Stack: []byte("goroutine 1 [running]:\n")}
+	for _, opts := range []options{{debug: true}, {trace: true}, {}} {
+		var out bytes.Buffer
+		opts.diagOut = &out
+		printStack(errors.Wrap(panicErr, "the call failed"), &opts)
+
+		if expected := opts.debug || opts.trace; (out.String() == string(panicErr.Stack)) != expected {
+			t.Errorf("expected the stack trace to be printed (%v) with --debug=%v --trace=%v but got %q", expected, opts.debug, opts.trace, out.String())
+		}
+	}
+}
@@ -0,0 +1,198 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ProfileError = "could not write the profile \"%v\""
+)
+
+// callStats are the measurements of a single call of the target for --time and --repeat
+type callStats struct {
+	wall   time.Duration
+	cpu    time.Duration
+	allocs uint64
+	bytes  uint64
+}
+
+// invoke calls the target, repeating the call for --repeat, measuring it for --time and profiling it for --cpuprofile, --memprofile and --trace-out.
+// The values and error of the last call are returned, the repetitions stop at the first call that fails
+func (opts *options) invoke(call Call, fn reflect.Value) ([]reflect.Value, error) {
+	if !opts.timing && opts.repeat <= 1 && opts.cpuProfile == "" && opts.memProfile == "" && opts.executionTrace == "" {
+		return callTarget(call.Name(), fn, call.Args)
+	}
+
+	stop, err := opts.startProfiles()
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+
+	repeat := opts.repeat
+	if repeat < 1 {
+		repeat = 1
+	}
+
+	var values []reflect.Value
+	samples := make([]callStats, 0, repeat)
+	for x := 0; x < repeat && err == nil; x++ {
+		var stats callStats
+		stats, values, err = measureCall(call, fn)
+		samples = append(samples, stats)
+	}
+
+	if opts.timing || opts.repeat > 1 {
+		writeCallStats(opts, call.Name(), samples)
+	}
+	return values, err
+}
+
+// measureCall calls the target once, measuring the wall time, the cpu time of the process and the allocations made during the call
+func measureCall(call Call, fn reflect.Value) (callStats, []reflect.Value, error) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	cpuBefore := cpuTime()
+	start := time.Now()
+
+	values, err := callTarget(call.Name(), fn, call.Args)
+
+	stats := callStats{wall: time.Since(start), cpu: cpuTime() - cpuBefore}
+	runtime.ReadMemStats(&after)
+	stats.allocs, stats.bytes = after.Mallocs-before.Mallocs, after.TotalAlloc-before.TotalAlloc
+	return stats, values, err
+}
+
+// writeCallStats writes the measurements of a single call, or the summary statistics of the repeated calls
+func writeCallStats(opts *options, name string, samples []callStats) {
+	w := opts.diagnostics()
+	if len(samples) == 1 {
+		s := samples[0]
+		fmt.Fprintf(w, "time: %v wall %v cpu %v allocs %v (%v bytes)\n", name, s.wall, s.cpu, s.allocs, s.bytes)
+		return
+	}
+
+	walls := make([]time.Duration, len(samples))
+	var cpu time.Duration
+	var allocs, bytes uint64
+	for x, s := range samples {
+		walls[x] = s.wall
+		cpu += s.cpu
+		allocs += s.allocs
+		bytes += s.bytes
+	}
+	n := len(samples)
+	min, mean, median, max, stddev := durationStats(walls)
+
+	fmt.Fprintf(w, "time: %v x%v\n", name, n)
+	fmt.Fprintf(w, "time:   wall min %v mean %v median %v max %v stddev %v\n", min, mean, median, max, stddev)
+	fmt.Fprintf(w, "time:   cpu mean %v\n", cpu/time.Duration(n))
+	fmt.Fprintf(w, "time:   allocs mean %v (%v bytes) per call\n", allocs/uint64(n), bytes/uint64(n))
+}
+
+// durationStats returns the min, mean, median, max and standard deviation of the durations
+func durationStats(durations []time.Duration) (time.Duration, time.Duration, time.Duration, time.Duration, time.Duration) {
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(x, y int) bool { return sorted[x] < sorted[y] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	mean := total / time.Duration(len(sorted))
+
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+
+	var variance float64
+	for _, d := range sorted {
+		variance += math.Pow(float64(d-mean), 2)
+	}
+	stddev := time.Duration(math.Sqrt(variance / float64(len(sorted))))
+
+	return sorted[0], mean, median, sorted[len(sorted)-1], stddev
+}
+
+// startProfiles starts the cpu profile and the execution trace, returning the func that stops them and writes the heap profile once the call is done
+func (opts *options) startProfiles() (func(), error) {
+	var stops []func() error
+	stop := func() {
+		for x := len(stops) - 1; x >= 0; x-- {
+			if err := stops[x](); err != nil {
+				printError(err)
+			}
+		}
+	}
+
+	if opts.cpuProfile != "" {
+		f, err := os.Create(opts.cpuProfile)
+		if err != nil {
+			return nil, errors.Wrapf(err, ProfileError, opts.cpuProfile)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, errors.Wrapf(err, ProfileError, opts.cpuProfile)
+		}
+		stops = append(stops, func() error {
+			pprof.StopCPUProfile()
+			return f.Close()
+		})
+	}
+
+	if opts.executionTrace != "" {
+		f, err := os.Create(opts.executionTrace)
+		if err == nil {
+			if err = trace.Start(f); err != nil {
+				f.Close()
+			}
+		}
+		if err != nil {
+			stop()
+			return nil, errors.Wrapf(err, ProfileError, opts.executionTrace)
+		}
+		stops = append(stops, func() error {
+			trace.Stop()
+			return f.Close()
+		})
+	}
+
+	if opts.memProfile != "" {
+		// the heap profile is written last, once the other profiles are stopped
+		stops = append([]func() error{func() error {
+			return writeHeapProfile(opts.memProfile)
+		}}, stops...)
+	}
+	return stop, nil
+}
+
+// writeHeapProfile writes the heap profile to the path, running a garbage collection first so the profile is up to date
+func writeHeapProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrapf(err, ProfileError, path)
+	}
+	defer f.Close()
+
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return errors.Wrapf(err, ProfileError, path)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestTiming(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	calls := 0
+	counted := func(a int, b int) int {
+		calls++
+		return a + b
+	}
+
+	testCases := []struct {
+		Name          string
+		Args          []string
+		ExpectedCalls int
+		Expected      []string
+	}{
+		{"Time", []string{"Fuego.Timing.Time", "3", "5", "--time"}, 1, []string{"time: func1 wall ", " cpu ", " allocs "}},
+		{"Repeat", []string{"Fuego.Timing.Repeat", "3", "5", "--repeat", "4"}, 4, []string{"time: func1 x4\n", "time:   wall min ", " median ", " stddev ", "time:   cpu mean ", "time:   allocs mean "}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			calls = 0
+			var out bytes.Buffer
+			opts := &options{diagOut: &out}
+			args, err := opts.parse(testCase.Args)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			values, err := dispatch(counted, args, opts)
+			if err != nil || len(values) != 1 || values[0].Interface() != 8 {
+				t.Fatalf("expected 8 but got %v %v", values, err)
+			}
+			if calls != testCase.ExpectedCalls {
+				t.Errorf("expected %v calls but got %v", testCase.ExpectedCalls, calls)
+			}
+			for _, expected := range testCase.Expected {
+				if !strings.Contains(out.String(), expected) {
+					t.Errorf("expected the timing to contain %q but got:\n%v", expected, out.String())
+				}
+			}
+		})
+	}
+
+	if _, err := (&options{}).parse([]string{"Fuego.Timing.InvalidRepeat", "--repeat=0"}); err == nil || !doErrorsMatch(errors.Errorf(InvalidOptionValueError, "0", "repeat"), err) {
+		t.Errorf("expected --repeat=0 to be invalid but got %v", err)
+	}
+}
+
+func TestRepeatStopsOnError(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	calls := 0
+	failing := func() int {
+		calls++
+		panic("failed")
+	}
+
+	opts := &options{diagOut: &bytes.Buffer{}, repeat: 5}
+	if _, err := dispatch(failing, []string{"Fuego.TestRepeatStopsOnError"}, opts); err == nil || calls != 1 {
+		t.Errorf("expected the repetitions to stop at the first failure but got %v calls and %v", calls, err)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	dir := t.TempDir()
+	cpuPath, memPath, tracePath := filepath.Join(dir, "cpu.prof"), filepath.Join(dir, "mem.prof"), filepath.Join(dir, "trace.out")
+
+	opts := &options{}
+	args, err := opts.parse([]string{"Fuego.TestProfiles", "3", "5", "--cpuprofile=" + cpuPath, "--memprofile", memPath, "--trace-out=" + tracePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	slowAdd := func(a int, b int) int {
+		time.Sleep(10 * time.Millisecond)
+		return a + b
+	}
+	if values, err := dispatch(slowAdd, args, opts); err != nil || values[0].Interface() != 8 {
+		t.Fatalf("expected 8 but got %v %v", values, err)
+	}
+
+	for _, path := range []string{cpuPath, memPath, tracePath} {
+		if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+			t.Errorf("expected the profile %v to be written but got %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ScriptOpenError   = "could not open the script \"%v\""
+	ScriptLineError   = "line \"%v\" of the script failed"
+	ScriptFailedError = "\"%v\" of the \"%v\" script commands failed"
+)
+
+// runScriptFile runs the --script file (or std in when the path is "-") against the targets
+func runScriptFile(targets interface{}, opts *options) error {
+	var script io.Reader = os.Stdin
+	if opts.script != "-" {
+		file, err := os.Open(opts.script)
+		if err != nil {
+			return errors.Wrapf(err, ScriptOpenError, opts.script)
+		}
+		defer file.Close()
+		script = file
+	}
+
+	return runScript(os.Args[0], targets, opts, script, os.Stdout)
+}
+
+// runScript dispatches each line of the script as a command line against a single shared instance of the targets.
+// Blank lines and # comments are skipped, errors are reported with their line number and stop the script when --stop-on-error is set.
+func runScript(programName string, targets interface{}, opts *options, script io.Reader, out io.Writer) error {
+	targets = addressableTargets(targets)
+
+	lineNumber, commandCount, failedCount := 0, 0, 0
+	var firstErr error
+
+	scanner := bufio.NewScanner(script)
+	for scanner.Scan() {
+		lineNumber++
+
+		isCommand, err := runScriptLine(programName, targets, opts, scanner.Text(), out)
+		if isCommand {
+			commandCount++
+		}
+		if err == nil {
+			continue
+		}
+
+		err = errors.Wrapf(err, ScriptLineError, lineNumber)
+		failedCount++
+		if firstErr == nil {
+			firstErr = err
+		}
+		printError(err)
+
+		if opts.stopOnError {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if failedCount == 1 {
+		return firstErr
+	} else if failedCount > 1 {
+		return errors.Errorf(ScriptFailedError, failedCount, commandCount)
+	}
+	return nil
+}
+
+// runScriptLine dispatches a single line of the script, reporting whether the line held a command rather than being blank or a comment
+func runScriptLine(programName string, targets interface{}, opts *options, line string, out io.Writer) (bool, error) {
+	args, err := splitCommandLine(line)
+	if err != nil {
+		return true, err
+	} else if len(args) == 0 {
+		return false, nil
+	}
+
+	lineOpts := *opts
+	lineOpts.script = ""
+	args, err = lineOpts.parse(append([]string{programName}, args...))
+	if err != nil {
+		return true, err
+	}
+
+	values, err := dispatch(targets, args, &lineOpts)
+	if err != nil {
+		return true, err
+	}
+
+	if err := printValues(out, values, &lineOpts); err != nil {
+		return true, err
+	}
+	// the streams are not drained when they are not printed
+	if err := Release(values); err != nil {
+		return true, err
+	}
+	// streamed results already end every element with a new line
+	if PrintToStdOut && lineOpts.format == nil && !hasStreamResults(values) {
+		_, err = fmt.Fprintln(out)
+	}
+	return true, err
+}
@@ -0,0 +1,61 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestRunScript(t *testing.T) {
+	PrintToStdErr = false
+
+	script := strings.Join([]string{
+		"# set the offset once and reuse it for every command",
+		"MyMath.Add 1 2 --Offset=10",
+		"",
+		"MyMath.Subtract 5 1",
+		"MyMath.Add 1 'two'",
+		"   AddInt 3 4   # trailing comment",
+		"MyMath.Add 1 2 --format='{{.}}!'",
+	}, "\n")
+
+	testCases := []struct {
+		Name           string
+		StopOnError    bool
+		ExpectedOutput string
+		ExpectedError  error
+	}{
+		{"ContinueOnError", false, "13\n-6\n7\n13!\n", errors.Errorf(ScriptLineError+": %v: %v", 5, ParameterListGenerationError, CannotConvertToDesiredValueTypeError)},
+		{"StopOnError", true, "13\n-6\n", errors.Errorf(ScriptLineError+": %v: %v", 5, ParameterListGenerationError, CannotConvertToDesiredValueTypeError)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			PrintToStdOut = true
+
+			var out bytes.Buffer
+			opts := &options{stopOnError: testCase.StopOnError}
+			err := runScript("fuego", []interface{}{AddInt, MyMath{Offset: 1}}, opts, strings.NewReader(script), &out)
+
+			if err == nil || !doErrorsMatch(testCase.ExpectedError, err) {
+				t.Errorf("Expected to receive the first error but instead the second error was returned: \n\t1) \"%v\"\n\t2) \"%v\"", testCase.ExpectedError, err)
+			}
+			if out.String() != testCase.ExpectedOutput {
+				t.Errorf("expected the script output %q but got %q", testCase.ExpectedOutput, out.String())
+			}
+		})
+	}
+
+	PrintToStdOut = false
+	err := runScript("fuego", AddInt, &options{}, strings.NewReader("1 2\n1 x\n'3\n"), &bytes.Buffer{})
+	if err == nil || !doErrorsMatch(errors.Errorf(ScriptFailedError, 2, 3), err) {
+		t.Errorf("expected a script failed error but got %v", err)
+	}
+}
@@ -0,0 +1,369 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	RouteNotFoundError        = "no function or method is exposed at \"%v\""
+	HTTPMethodNotAllowedError = "the http method \"%v\" is not allowed, only \"POST\" is supported"
+	InvalidRequestBodyError   = "the request body must be a JSON array or object of arguments"
+	UnknownArgumentError      = "\"%v\" is not a parameter or attribute of \"%v\""
+	MissingArgumentError      = "the parameter \"%v\" of \"%v\" was not passed in"
+	UnnamedParamsError        = "the parameter names of \"%v\" are not known, pass the arguments as a JSON array or declare the names with fuego.ParamNames"
+)
+
+// the types of errors returned by the http server
+const (
+	requestErrorType     = "request"
+	notFoundErrorType    = "not_found"
+	argumentsErrorType   = "arguments"
+	panicErrorType       = "panic"
+	targetErrorType      = "target"
+	lifecycleErrorType   = "lifecycle"
+	constructorErrorType = "constructor"
+)
+
+// errorType is the reflect type of the error interface, used to detect functions and methods that return an error
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// serverResult is the JSON body returned by the http server for a successful call
+type serverResult struct {
+	Results []interface{} `json:"results"`
+}
+
+// serverError is the JSON body returned by the http server for a failed call
+type serverError struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// handler exposes the targets as http endpoints, ie. POST /MyMath/Add with a JSON body of arguments
+type handler struct {
+	targets  interface{}
+	opts     *options
+	commands map[string]command
+}
+
+// Handler returns an http.Handler exposing the targets as JSON endpoints. Functions are exposed at /<Function> and struct methods at /<Struct>/<Method>.
+// The request body is either a JSON array of positional arguments or a JSON object of named parameters and struct attributes, ie. {"a": 5, "b": 3, "Offset": 2}.
+// The OpenAPI document describing the endpoints is served at GET /openapi.json
+func Handler(targets interface{}) http.Handler {
+	return newHandler(targets, &options{})
+}
+
+// newHandler creates the http handler for the targets using the fuego options passed in on the command line
+func newHandler(targets interface{}, opts *options) *handler {
+	// the args of requests are never read from the files or std in of the server
+	handlerOpts := *opts
+	handlerOpts.localArgs = false
+
+	h := &handler{targets: targets, opts: &handlerOpts, commands: map[string]command{}}
+	for _, cmd := range listCommands(targets) {
+		h.commands[cmd.Name] = cmd
+	}
+	return h
+}
+
+// runServer serves the targets over http on the --serve address until SIGINT / SIGTERM is received
+func runServer(targets interface{}, opts *options) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	server := &http.Server{Addr: opts.serve, Handler: newHandler(targets, opts)}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return err
+	case <-ctx.Done():
+		return server.Shutdown(context.Background())
+	}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && r.URL.Path == openAPIPath {
+		serveOpenAPI(w, h.targets)
+		return
+	} else if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeServerError(w, http.StatusMethodNotAllowed, requestErrorType, errors.Errorf(HTTPMethodNotAllowedError, r.Method))
+		return
+	}
+
+	cmd, ok := h.commands[strings.Replace(strings.Trim(r.URL.Path, "/"), "/", ".", -1)]
+	if !ok {
+		writeServerError(w, http.StatusNotFound, notFoundErrorType, errors.Errorf(RouteNotFoundError, r.URL.Path))
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeServerError(w, http.StatusBadRequest, requestErrorType, err)
+		return
+	}
+
+	args, err := commandArgs(cmd, body)
+	if err != nil {
+		writeServerError(w, http.StatusBadRequest, argumentsErrorType, err)
+		return
+	}
+
+	// every request gets its own copy of the struct targets so attributes set by one request are not seen by another
+	callOpts := *h.opts
+	callOpts.baseContext = r.Context()
+	values, err := dispatch(cloneTargets(h.targets), append([]string{os.Args[0], cmd.Name}, args...), &callOpts)
+	if err != nil {
+		status, errType := dispatchErrorType(err)
+		writeServerError(w, status, errType, err)
+		return
+	}
+
+	results, err := targetResults(values)
+	if err != nil {
+		writeServerError(w, http.StatusInternalServerError, targetErrorType, err)
+		return
+	}
+	writeServerJSON(w, http.StatusOK, serverResult{Results: results})
+}
+
+// targetResults converts the returned values into the results of a call, a trailing error returned by the target is reported as a failed call rather than as a result
+func targetResults(values []reflect.Value) ([]interface{}, error) {
+	// channels and iterators are not drained by remote callers so they are released right away
+	if err := Release(values); err != nil {
+		return nil, err
+	}
+	if len(values) > 0 && values[len(values)-1].Type() == errorType {
+		if targetErr := values[len(values)-1]; !targetErr.IsNil() {
+			return nil, targetErr.Interface().(error)
+		}
+		values = values[:len(values)-1]
+	}
+
+	results := make([]interface{}, len(values))
+	for x, val := range values {
+		results[x] = val.Interface()
+	}
+	return results, nil
+}
+
+// commandArgs converts the JSON body into the command line args for the command, positional parameters followed by --<attribute>=<value> flags
+func commandArgs(cmd command, body []byte) ([]string, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, nil
+	}
+
+	var positional []json.RawMessage
+	if body[0] == '[' {
+		if err := json.Unmarshal(body, &positional); err != nil {
+			return nil, errors.Wrap(err, InvalidRequestBodyError)
+		}
+
+		return terminatedArgs(nil, positional), nil
+	}
+
+	var named map[string]json.RawMessage
+	if err := json.Unmarshal(body, &named); err != nil {
+		return nil, errors.Wrap(err, InvalidRequestBodyError)
+	}
+
+	// generated names (ie. arg0) are not part of the contract so the parameters can only be passed by position
+	paramNames, _ := cmd.Params()
+	if len(paramNames) > 0 && !cmd.HasParamNames() {
+		return nil, errors.Errorf(UnnamedParamsError, cmd.Name)
+	}
+	params := make([]json.RawMessage, 0, len(paramNames))
+	for _, paramName := range paramNames {
+		val, ok := named[paramName]
+		if !ok {
+			return nil, errors.Errorf(MissingArgumentError, paramName, cmd.Name)
+		}
+		params = append(params, val)
+		delete(named, paramName)
+	}
+
+	flags := make([]string, 0, len(named))
+	for name, val := range named {
+		if cmd.Struct == nil {
+			return nil, errors.Errorf(UnknownArgumentError, name, cmd.Name)
+		}
+		if _, ok := attributeType(cmd.Struct, name, false); !ok {
+			return nil, errors.Errorf(UnknownArgumentError, name, cmd.Name)
+		}
+		flags = append(flags, "--"+name+"="+jsonArgString(val))
+	}
+	return terminatedArgs(flags, params), nil
+}
+
+// terminatedArgs places the positional values after a "--" terminator so values that look like flags (ie. "--ID=5") are passed to the parameters
+// rather than setting struct attributes, the attribute flags come before the terminator
+func terminatedArgs(flags []string, positional []json.RawMessage) []string {
+	if len(positional) == 0 {
+		return flags
+	}
+
+	args := append(flags, "--")
+	for _, val := range positional {
+		args = append(args, jsonArgString(val))
+	}
+	return args
+}
+
+// jsonArgString converts a JSON value into the string form fuego converts args from, unquoting strings and keeping other values as their JSON text
+func jsonArgString(val json.RawMessage) string {
+	var str string
+	if err := json.Unmarshal(val, &str); err == nil {
+		return str
+	}
+	return string(bytes.TrimSpace(val))
+}
+
+// cloneTargets copies the struct (and pointer to struct) targets into new pointers so each call works on its own instance.
+// The copy is deep so attributes set through nested pointers, maps and slices (ie. --Labels[env]=prod) are not seen by the original or other calls
+func cloneTargets(targets interface{}) interface{} {
+	switch t := targets.(type) {
+	case []interface{}:
+		cloned := make([]interface{}, len(t))
+		for x, target := range t {
+			cloned[x] = cloneTargets(target)
+		}
+		return cloned
+	default:
+		targetVal := reflect.ValueOf(targets)
+		if targetVal.Kind() == reflect.Ptr && targetVal.Elem().Kind() == reflect.Struct {
+			targetVal = targetVal.Elem()
+		}
+		if targetVal.Kind() != reflect.Struct {
+			return targets
+		}
+
+		ptr := reflect.New(targetVal.Type())
+		ptr.Elem().Set(deepCopy(targetVal, map[copiedPointer]reflect.Value{}))
+		return ptr.Interface()
+	}
+}
+
+// copiedPointer identifies a pointer that was already copied by deepCopy, so shared and self referencing pointers are copied once
+type copiedPointer struct {
+	t reflect.Type
+	p uintptr
+}
+
+// deepCopy copies the value, following pointers, maps, slices and arrays so the copy shares nothing an attribute could be set through with the original.
+// Unexported fields cannot be set by flags (nor through reflect) so they are copied as they are, ie. a client or connection held by the target
+func deepCopy(val reflect.Value, copied map[copiedPointer]reflect.Value) reflect.Value {
+	switch val.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			return val
+		}
+		key := copiedPointer{t: val.Type(), p: val.Pointer()}
+		if ptr, ok := copied[key]; ok {
+			return ptr
+		}
+		ptr := reflect.New(val.Type().Elem())
+		copied[key] = ptr
+		ptr.Elem().Set(deepCopy(val.Elem(), copied))
+		return ptr
+	case reflect.Struct:
+		cloned := reflect.New(val.Type()).Elem()
+		cloned.Set(val)
+		for x := 0; x < cloned.NumField(); x++ {
+			if field := cloned.Field(x); field.CanSet() {
+				field.Set(deepCopy(field, copied))
+			}
+		}
+		return cloned
+	case reflect.Map:
+		if val.IsNil() {
+			return val
+		}
+		cloned := reflect.MakeMapWithSize(val.Type(), val.Len())
+		for iter := val.MapRange(); iter.Next(); {
+			cloned.SetMapIndex(iter.Key(), deepCopy(iter.Value(), copied))
+		}
+		return cloned
+	case reflect.Slice:
+		if val.IsNil() {
+			return val
+		}
+		cloned := reflect.MakeSlice(val.Type(), val.Len(), val.Len())
+		for x := 0; x < val.Len(); x++ {
+			cloned.Index(x).Set(deepCopy(val.Index(x), copied))
+		}
+		return cloned
+	case reflect.Array:
+		cloned := reflect.New(val.Type()).Elem()
+		for x := 0; x < val.Len(); x++ {
+			cloned.Index(x).Set(deepCopy(val.Index(x), copied))
+		}
+		return cloned
+	default:
+		return val
+	}
+}
+
+// dispatchErrorType returns the status code and error type of an error returned by dispatch, panics and failed lifecycle hooks or constructors
+// are failures of the target while any other error is caused by the arguments of the request
+func dispatchErrorType(err error) (int, string) {
+	var hookErr *HookError
+	switch {
+	case isPanicError(err):
+		return http.StatusInternalServerError, panicErrorType
+	case errors.As(err, &hookErr) && hookErr.Hook == "Constructor":
+		return http.StatusInternalServerError, constructorErrorType
+	case errors.As(err, &hookErr):
+		return http.StatusInternalServerError, lifecycleErrorType
+	default:
+		return http.StatusBadRequest, argumentsErrorType
+	}
+}
+
+// isPanicError reports whether the error was caused by a recovered panic
+func isPanicError(err error) bool {
+	_, isPanic := errors.Cause(err).(*PanicError)
+	return isPanic
+}
+
+// writeServerError writes the typed JSON error with the status code
+func writeServerError(w http.ResponseWriter, status int, errType string, err error) {
+	var body serverError
+	body.Error.Type = errType
+	body.Error.Message = err.Error()
+	writeServerJSON(w, status, body)
+}
+
+// writeServerJSON writes the value as the JSON response with the status code
+func writeServerJSON(w http.ResponseWriter, status int, v interface{}) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		out, _ = json.Marshal(map[string]map[string]string{"error": {"type": targetErrorType, "message": err.Error()}})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(out, '\n'))
+}
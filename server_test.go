@@ -0,0 +1,225 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func CheckPositive(a int) (int, error) {
+	if a < 0 {
+		return 0, errors.New("the number is negative")
+	}
+	return a, nil
+}
+
+func Echo(value string) string {
+	return value
+}
+
+func TestHandler(t *testing.T) {
+	server := httptest.NewServer(Handler([]interface{}{AddInt, DivideInt, CheckPositive, &MyMath{Offset: 1}, &Store{}, Constructor(NewClient)}))
+	defer server.Close()
+
+	testCases := []struct {
+		Name           string
+		Method         string
+		Path           string
+		Body           string
+		ExpectedStatus int
+		ExpectedBody   string
+	}{
+		{"PositionalArguments", http.MethodPost, "/MyMath/Add", "[5, 3]", http.StatusOK, `{"results":[9]}`},
+		{"NamedArgumentsAndAttributes", http.MethodPost, "/MyMath/Add", `{"a": 5, "b": "3", "Offset": 2}`, http.StatusOK, `{"results":[10]}`},
+		{"AttributesNotShared", http.MethodPost, "/MyMath/Add", `{"a": 5, "b": 3}`, http.StatusOK, `{"results":[9]}`},
+		{"Function", http.MethodPost, "/AddInt", `{"b": 2, "a": 1}`, http.StatusOK, `{"results":[3]}`},
+		{"TrailingNilError", http.MethodPost, "/CheckPositive", `[4]`, http.StatusOK, `{"results":[4]}`},
+		{"TargetError", http.MethodPost, "/CheckPositive", `[-4]`, http.StatusInternalServerError, `{"error":{"type":"target","message":"the number is negative"}}`},
+		{"Panic", http.MethodPost, "/DivideInt", `[1, 0]`, http.StatusInternalServerError, `{"error":{"type":"panic","message":"the call to \"DivideInt\" panicked: runtime error: integer divide by zero"}}`},
+		{"InvalidArgument", http.MethodPost, "/AddInt", `[1, "hi"]`, http.StatusBadRequest, `{"error":{"type":"arguments","message":"could not generate the necessary function parameters list: cannot convert \"hi\" to \"int\" as needed"}}`},
+		{"InsufficientArguments", http.MethodPost, "/AddInt", `[1]`, http.StatusBadRequest, `{"error":{"type":"arguments","message":"not enough arguments were passed in to setup the function parameter values"}}`},
+		{"MissingNamedArgument", http.MethodPost, "/AddInt", `{"a": 1}`, http.StatusBadRequest, `{"error":{"type":"arguments","message":"the parameter \"b\" of \"AddInt\" was not passed in"}}`},
+		{"InvalidAttribute", http.MethodPost, "/MyMath/Add", `{"a": 5, "b": 3, "Offset": "x"}`, http.StatusBadRequest, `{"error":{"type":"arguments","message":"the flag \"--Offset\" could not set the attribute of struct \"MyMath\": cannot convert \"x\" to \"float64\" as needed"}}`},
+		{"FlagLikeNamedArgument", http.MethodPost, "/Store/Get", `{"Path": "/tmp", "key": "--FailInit"}`, http.StatusOK, `{"results":["/tmp/--FailInit"]}`},
+		{"FlagLikePositionalArgument", http.MethodPost, "/MyMath/Add", `["--Offset=5", 3]`, http.StatusBadRequest, `{"error":{"type":"arguments","message":"could not generate the necessary function parameters list: cannot convert \"--Offset=5\" to \"float64\" as needed"}}`},
+		{"LifecycleError", http.MethodPost, "/Store/Get", `["a"]`, http.StatusInternalServerError, `{"error":{"type":"lifecycle","message":"the attributes of struct \"Store\" are not valid: a path is required"}}`},
+		{"ConstructorError", http.MethodPost, "/Client/Get", `["/"]`, http.StatusInternalServerError, `{"error":{"type":"constructor","message":"the constructor \"NewClient\" failed: a host is required"}}`},
+		{"UnknownNamedArgument", http.MethodPost, "/AddInt", `{"a": 1, "b": 2, "c": 3}`, http.StatusBadRequest, `{"error":{"type":"arguments","message":"\"c\" is not a parameter or attribute of \"AddInt\""}}`},
+		{"InvalidBody", http.MethodPost, "/AddInt", `{"a": `, http.StatusBadRequest, `{"error":{"type":"arguments","message":"the request body must be a JSON array or object of arguments: unexpected end of JSON input"}}`},
+		{"NotFound", http.MethodPost, "/MyMath/Divide", `[]`, http.StatusNotFound, `{"error":{"type":"not_found","message":"no function or method is exposed at \"/MyMath/Divide\""}}`},
+		{"MethodNotAllowed", http.MethodGet, "/AddInt", ``, http.StatusMethodNotAllowed, `{"error":{"type":"request","message":"the http method \"GET\" is not allowed, only \"POST\" is supported"}}`},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			req, err := http.NewRequest(testCase.Method, server.URL+testCase.Path, strings.NewReader(testCase.Body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			var body strings.Builder
+			if _, err := io.Copy(&body, resp.Body); err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.StatusCode != testCase.ExpectedStatus {
+				t.Errorf("expected the status %d but got %d", testCase.ExpectedStatus, resp.StatusCode)
+			}
+			if strings.TrimSpace(body.String()) != testCase.ExpectedBody {
+				t.Errorf("expected the body %s but got %s", testCase.ExpectedBody, body.String())
+			}
+			if contentType := resp.Header.Get("Content-Type"); contentType != "application/json" {
+				t.Errorf("expected a JSON response but got %q", contentType)
+			}
+		})
+	}
+}
+
+func TestHandlerArgsNotExpanded(t *testing.T) {
+	secretPath := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(secretPath, []byte("TOPSECRET"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// the handler is created from the options of the command line (ie. --serve) which read "@<path>" and "-" args locally
+	server := httptest.NewServer(newHandler(Echo, &options{localArgs: true, stdin: strings.NewReader("STDIN")}))
+	defer server.Close()
+
+	for _, arg := range []string{"@" + secretPath, "-"} {
+		resp, err := http.Post(server.URL+"/Echo", "application/json", strings.NewReader(`["`+arg+`"]`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if expected := `{"results":["` + arg + `"]}`; strings.TrimSpace(string(body)) != expected {
+			t.Errorf("expected the arg to be passed through literally as %s but got %s", expected, body)
+		}
+	}
+}
+
+type Multiplier struct {
+	Factor int
+}
+
+func (m Multiplier) Scale(value int) int {
+	return value * m.Factor
+}
+
+func TestHandlerParamNames(t *testing.T) {
+	post := func(handler http.Handler, body string) string {
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		resp, err := http.Post(server.URL+"/Scale", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(string(respBody))
+	}
+
+	// the source of method values is not available so their parameter names are generated and only positional arguments are accepted
+	scale := Multiplier{Factor: 2}.Scale
+	if body := post(Handler(scale), `[3]`); body != `{"results":[6]}` {
+		t.Errorf("expected the positional arguments to be accepted but got %s", body)
+	}
+	if body, expected := post(Handler(scale), `{"arg0": 3}`), `{"error":{"type":"arguments","message":"the parameter names of \"Scale\" are not known, pass the arguments as a JSON array or declare the names with fuego.ParamNames"}}`; body != expected {
+		t.Errorf("expected the body %s but got %s", expected, body)
+	}
+	if tools := Tools(scale); len(tools) != 0 {
+		t.Errorf("expected the method without parameter names to be left out of the tools but got %+v", tools)
+	}
+
+	ParamNames(Multiplier.Scale, "value")
+	if body := post(Handler(scale), `{"value": 3}`); body != `{"results":[6]}` {
+		t.Errorf("expected the declared parameter names to be accepted but got %s", body)
+	}
+	if tools := Tools(scale); len(tools) != 1 || tools[0].InputSchema["required"] == nil {
+		t.Errorf("expected the method with declared parameter names to be a tool but got %+v", tools)
+	}
+
+	defer func() {
+		if r := recover(); r == nil || r.(error).Error() != `"Scale" takes 1 parameters but 2 parameter names were declared` {
+			t.Errorf("expected ParamNames to panic on the wrong number of names but got %v", r)
+		}
+	}()
+	ParamNames(Multiplier.Scale, "value", "extra")
+}
+
+type Labeled struct {
+	Labels map[string]string
+	DB     *DBConfig
+	Ports  []int
+}
+
+func (l Labeled) Describe() string {
+	return fmt.Sprintf("env=%v team=%v db=%v ports=%v", l.Labels["env"], l.Labels["team"], l.DB.Host, l.Ports)
+}
+
+func TestHandlerTargetsNotShared(t *testing.T) {
+	target := &Labeled{Labels: map[string]string{"team": "core"}, DB: &DBConfig{Host: "db"}, Ports: []int{80}}
+	server := httptest.NewServer(Handler(target))
+	defer server.Close()
+
+	post := func(body string) string {
+		resp, err := http.Post(server.URL+"/Labeled/Describe", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Error(err)
+			return ""
+		}
+		defer resp.Body.Close()
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		return strings.TrimSpace(string(respBody))
+	}
+
+	// concurrent requests set map, pointer and slice attributes on their own copy of the target
+	var wg sync.WaitGroup
+	for x := 0; x < 10; x++ {
+		wg.Add(1)
+		go func(x int) {
+			defer wg.Done()
+			body := fmt.Sprintf(`{"Labels[env]": "e%d", "DB.Host": "h%d", "Ports[0]": %d}`, x, x, x)
+			if got, expected := post(body), fmt.Sprintf(`{"results":["env=e%d team=core db=h%d ports=[%d]"]}`, x, x, x); got != expected {
+				t.Errorf("expected the body %s but got %s", expected, got)
+			}
+		}(x)
+	}
+	wg.Wait()
+
+	if got, expected := post(`[]`), `{"results":["env= team=core db=db ports=[80]"]}`; got != expected {
+		t.Errorf("expected the attributes of earlier requests to be left out but got %s", got)
+	}
+	if len(target.Labels) != 1 || target.DB.Host != "db" || target.Ports[0] != 80 {
+		t.Errorf("expected the original target to be left unchanged but got %+v %+v", target, target.DB)
+	}
+}
@@ -0,0 +1,188 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	StreamOpenError   = "could not open \"%v\" for the parameter of type \"%v\""
+	StreamCloseError  = "could not close \"%v\""
+	RemoteStreamError = "the parameter of type \"%v\" is bound to a file or std stream, which is only allowed from the local command line"
+)
+
+var (
+	readerType      = reflect.TypeOf((*io.Reader)(nil)).Elem()
+	readCloserType  = reflect.TypeOf((*io.ReadCloser)(nil)).Elem()
+	writerType      = reflect.TypeOf((*io.Writer)(nil)).Elem()
+	writeCloserType = reflect.TypeOf((*io.WriteCloser)(nil)).Elem()
+)
+
+// gzipExtension is the file extension of streams that are transparently compressed or decompressed
+const gzipExtension = ".gz"
+
+// stream is a file opened for an io.Reader or io.Writer parameter, it is closed once the call returns
+type stream struct {
+	path    string
+	closers []io.Closer
+}
+
+// Close closes the gzip stream (flushing a gzip writer) before the file under it, files already closed by the called function are not an error
+func (s *stream) Close() error {
+	var err error
+	for x := len(s.closers) - 1; x >= 0; x-- {
+		if closeErr := s.closers[x].Close(); closeErr != nil && !errors.Is(closeErr, os.ErrClosed) && err == nil {
+			err = errors.Wrapf(closeErr, StreamCloseError, s.path)
+		}
+	}
+	return err
+}
+
+// nopWriteCloser binds std out to io.WriteCloser parameters without letting the called function close it
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// isReaderType reports whether the parameter is bound to std in or a file rather than converted from the arg
+func isReaderType(t reflect.Type) bool {
+	return t == readerType || t == readCloserType
+}
+
+// isWriterType reports whether the parameter is bound to std out or a created file rather than converted from the arg
+func isWriterType(t reflect.Type) bool {
+	return t == writerType || t == writeCloserType
+}
+
+// isStreamType reports whether the parameter is an io.Reader, io.ReadCloser, io.Writer or io.WriteCloser
+func isStreamType(t reflect.Type) bool {
+	return isReaderType(t) || isWriterType(t)
+}
+
+// defaultStreamParams binds the io.Reader and io.Writer parameters that are not passed in to std in and std out, ie. Compress(r io.Reader, w io.Writer)
+// can be called with no args at all. Only trailing stream parameters are bound so the other parameters keep their positions.
+// Remote callers (--serve, --jsonrpc and CallTool) can not call targets with stream parameters at all, they would otherwise pick the files the process opens and creates
+func (opts *options) defaultStreamParams(paramTypes []reflect.Type, params []string) ([]string, error) {
+	for x := range paramTypes {
+		if !opts.localArgs && isStreamType(paramTypes[x]) {
+			return nil, errors.Errorf(RemoteStreamError, paramTypes[x])
+		}
+	}
+
+	for x := len(params); x < len(paramTypes) && isStreamType(paramTypes[x]); x++ {
+		params = append(params, stdStreamArg)
+	}
+	return params, nil
+}
+
+// convertParams converts the args to the parameters of the function, binding io.Reader and io.Writer parameters to files (or std in / std out for "-")
+// and reading the other parameters from std in or files first (see expandArgValue). The opened files are returned to be closed once the call returns
+func (opts *options) convertParams(paramTypes []reflect.Type, params []string) ([]reflect.Value, []io.Closer, error) {
+	values := make([]reflect.Value, len(paramTypes))
+	var streams []io.Closer
+
+	for x, paramType := range paramTypes {
+		if isStreamType(paramType) {
+			val, s, err := opts.openStream(paramType, params[x])
+			if err != nil {
+				_ = closeStreams(streams)
+				return nil, nil, err
+			}
+			if s != nil {
+				streams = append(streams, s)
+			}
+			opts.tracef("the parameter %v (%v) is bound to %q", x, paramType, params[x])
+			values[x] = val
+			continue
+		}
+
+		value, err := opts.expandArgValue(params[x])
+		if err != nil {
+			_ = closeStreams(streams)
+			return nil, nil, err
+		}
+		vals, err := convertStringsToReflectValues([]reflect.Kind{paramType.Kind()}, []string{value})
+		if err != nil {
+			_ = closeStreams(streams)
+			return nil, nil, errors.Wrap(err, ParameterListGenerationError)
+		}
+		values[x] = vals[0]
+	}
+	return values, streams, nil
+}
+
+// openStream opens the file at the path for reading or creates it for writing, wrapping it with gzip for ".gz" files. The path "-" is bound to std in or std out,
+// which are never closed. The returned stream is nil when nothing needs to be closed
+func (opts *options) openStream(paramType reflect.Type, path string) (reflect.Value, io.Closer, error) {
+	if path == stdStreamArg {
+		if isReaderType(paramType) {
+			return reflect.ValueOf(io.NopCloser(opts.input())).Convert(paramType), nil, nil
+		}
+		return reflect.ValueOf(nopWriteCloser{opts.output()}).Convert(paramType), nil, nil
+	}
+
+	s := &stream{path: path}
+	if isReaderType(paramType) {
+		file, err := os.Open(path)
+		if err != nil {
+			return reflect.Value{}, nil, errors.Wrapf(err, StreamOpenError, path, paramType)
+		}
+		s.closers = append(s.closers, file)
+		if !strings.HasSuffix(path, gzipExtension) {
+			return reflect.ValueOf(file).Convert(paramType), s, nil
+		}
+
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			_ = s.Close()
+			return reflect.Value{}, nil, errors.Wrapf(err, StreamOpenError, path, paramType)
+		}
+		s.closers = append(s.closers, gz)
+		return reflect.ValueOf(gz).Convert(paramType), s, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return reflect.Value{}, nil, errors.Wrapf(err, StreamOpenError, path, paramType)
+	}
+	s.closers = append(s.closers, file)
+	if !strings.HasSuffix(path, gzipExtension) {
+		return reflect.ValueOf(file).Convert(paramType), s, nil
+	}
+
+	gz := gzip.NewWriter(file)
+	s.closers = append(s.closers, gz)
+	return reflect.ValueOf(gz).Convert(paramType), s, nil
+}
+
+// closeStreams closes the streams opened for the parameters of a call, returning the first error
+func closeStreams(streams []io.Closer) error {
+	var err error
+	for _, s := range streams {
+		if closeErr := s.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+// output returns the writer io.Writer parameters bound to "-" write to, os.Stdout unless it is set on the options
+func (opts *options) output() io.Writer {
+	if opts.stdout != nil {
+		return opts.stdout
+	}
+	return os.Stdout
+}
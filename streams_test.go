@@ -0,0 +1,155 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func Shout(r io.Reader, w io.Writer) (int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	return w.Write(bytes.ToUpper(data))
+}
+
+func CountLines(r io.ReadCloser) (int, error) {
+	defer r.Close()
+
+	lines := 0
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines++
+	}
+	return lines, scanner.Err()
+}
+
+func TestStreamParams(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	dir := t.TempDir()
+	inPath := filepath.Join(dir, "in.txt")
+	if err := os.WriteFile(inPath, []byte("hello\nworld\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	_, _ = gz.Write([]byte("zipped"))
+	_ = gz.Close()
+	gzPath := filepath.Join(dir, "in.txt.gz")
+	if err := os.WriteFile(gzPath, gzipped.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	missingPath := filepath.Join(dir, "missing.txt")
+	_, missingErr := os.Open(missingPath)
+
+	testCases := []struct {
+		Name          string
+		Targets       interface{}
+		Args          []string
+		Stdin         string
+		Expected      interface{}
+		ExpectedOut   string
+		OutPath       string
+		ExpectedError error
+	}{
+		{"StdStreams", Shout, []string{"Fuego.Streams.StdStreams"}, "abc", 3, "ABC", "", nil},
+		{"Dashes", Shout, []string{"Fuego.Streams.Dashes", "-", "-"}, "abc", 3, "ABC", "", nil},
+		{"Files", Shout, []string{"Fuego.Streams.Files", inPath, filepath.Join(dir, "out.txt")}, "", 12, "HELLO\nWORLD\n", filepath.Join(dir, "out.txt"), nil},
+		{"FileToStdout", Shout, []string{"Fuego.Streams.FileToStdout", inPath}, "", 12, "HELLO\nWORLD\n", "", nil},
+		{"Gzip", Shout, []string{"Fuego.Streams.Gzip", gzPath, filepath.Join(dir, "out.txt.gz")}, "", 6, "ZIPPED", filepath.Join(dir, "out.txt.gz"), nil},
+		{"ClosedByCall", CountLines, []string{"Fuego.Streams.ClosedByCall", inPath}, "", 2, "", "", nil},
+		{"MissingFile", Shout, []string{"Fuego.Streams.MissingFile", missingPath}, "", nil, "", "", errors.Wrapf(missingErr, StreamOpenError, missingPath, readerType)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var out bytes.Buffer
+			opts := &options{localArgs: true, stdin: strings.NewReader(testCase.Stdin), stdout: &out}
+			args, err := opts.parse(testCase.Args)
+			var values []reflect.Value
+			if err == nil {
+				values, err = dispatch(testCase.Targets, args, opts)
+			}
+
+			if testCase.ExpectedError != nil {
+				if err == nil || !doErrorsMatch(testCase.ExpectedError, err) {
+					t.Errorf("Expected to receive the first error but instead the second error was returned: \n\t1) \"%v\"\n\t2) \"%v\"", testCase.ExpectedError, err)
+				}
+				return
+			} else if err != nil {
+				t.Fatalf("Error is not expected but got %v", err)
+			} else if len(values) != 2 || !reflect.DeepEqual(values[0].Interface(), testCase.Expected) {
+				t.Errorf("expected %v but got %v", testCase.Expected, values)
+			}
+
+			written := out.String()
+			if testCase.OutPath != "" {
+				written = readStream(t, testCase.OutPath)
+			}
+			if written != testCase.ExpectedOut {
+				t.Errorf("expected %q to be written but got %q", testCase.ExpectedOut, written)
+			}
+		})
+	}
+}
+
+func TestStreamParamsRemote(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "out.txt")
+
+	// remote callers can neither pick the files the process opens or creates nor be bound to its std in and std out
+	var out bytes.Buffer
+	request := `{"jsonrpc":"2.0","method":"Shout","params":["/etc/passwd",` + strconv.Quote(outPath) + `],"id":1}` + "\n" + `{"jsonrpc":"2.0","method":"Shout","id":2}`
+	_ = serveJSONRPC(Shout, &options{localArgs: true}, strings.NewReader(request), &out)
+
+	message := strings.Replace(fmt.Sprintf(RemoteStreamError, readerType), `"`, `\"`, -1)
+	expected := `{"jsonrpc":"2.0","error":{"code":-32602,"message":"` + message + `"},"id":1}` + "\n" + `{"jsonrpc":"2.0","error":{"code":-32602,"message":"` + message + `"},"id":2}`
+	if strings.TrimSpace(out.String()) != expected {
+		t.Errorf("expected the response %s but got %s", expected, out.String())
+	}
+	if _, err := os.Stat(outPath); !os.IsNotExist(err) {
+		t.Errorf("expected the remote call not to create %v but got %v", outPath, err)
+	}
+
+	if _, err := CallTool(Shout, []byte(`{"name": "Shout", "input": {"r": "-", "w": "-"}}`)); err == nil || !doErrorsMatch(errors.Errorf(RemoteStreamError, readerType), err) {
+		t.Errorf("expected the tool call to be rejected but got %v", err)
+	}
+}
+
+// readStream reads the file written by a call, decompressing ".gz" files
+func readStream(t *testing.T, path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, gzipExtension) {
+		if r, err = gzip.NewReader(file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
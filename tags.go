@@ -0,0 +1,134 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	RequiredAttributeError = "the attribute \"%v\" for struct \"%v\" is required"
+	DefaultAttributeError  = "the default \"%v\" of the attribute \"%v\" for struct \"%v\" is invalid"
+)
+
+// fuegoTag holds the options set on a struct attribute with the `fuego:"..."` tag, ie. `fuego:"name=offset,short=o,default=3,required,usage=added to results"`.
+// The usage is always the last option as it takes the rest of the tag, so it may contain commas.
+type fuegoTag struct {
+	// name is the flag name of the attribute in place of the go field name, ie. --offset
+	name string
+	// short is the single letter alias of the attribute, ie. -o=3
+	short string
+	// def is the value of the attribute when it is not set by the config, environment or flags
+	def        string
+	hasDefault bool
+	// required attributes must be set (to a non zero value) before a method is called
+	required bool
+	// usage describes the attribute in --help
+	usage string
+	// env is the environment variable the attribute is bound to in place of the derived <STRUCT>_<ATTRIBUTE> name
+	env string
+}
+
+// parseFuegoTag parses the comma separated key=value options of the `fuego:"..."` tag of the struct attribute
+func parseFuegoTag(field reflect.StructField) fuegoTag {
+	var tag fuegoTag
+
+	rest := field.Tag.Get("fuego")
+	for rest != "" {
+		var option string
+		if strings.HasPrefix(strings.TrimSpace(rest), "usage=") {
+			option, rest = strings.TrimSpace(rest), ""
+		} else {
+			option, rest, _ = strings.Cut(rest, ",")
+		}
+
+		key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch key {
+		case "name":
+			tag.name = value
+		case "short":
+			tag.short = value
+		case "default":
+			tag.def, tag.hasDefault = value, true
+		case "required":
+			tag.required = true
+		case "usage":
+			tag.usage = value
+		case "env":
+			tag.env = value
+		}
+	}
+	return tag
+}
+
+// attributeName returns the flag name of the struct attribute, the `fuego:"name=<name>"` tag or the go field name
+func attributeName(field reflect.StructField) string {
+	if tag := parseFuegoTag(field); tag.name != "" {
+		return tag.name
+	}
+	return field.Name
+}
+
+// applyDefaults sets the struct attributes within the targets that are still zero to the `fuego:"default=<value>"` of their tag, including nested and embedded attributes.
+// Struct values are copied into pointers when there is a default to set, defaults that can not be converted are reported and skipped.
+func applyDefaults(targets interface{}) interface{} {
+	if !hasDefaultAttributes(targets) {
+		return targets
+	}
+
+	targets = addressableTargets(targets)
+	for _, target := range targetList(targets) {
+		structVal := reflect.ValueOf(target)
+		if structVal.Kind() != reflect.Ptr || structVal.Elem().Kind() != reflect.Struct {
+			continue
+		}
+		structVal = structVal.Elem()
+
+		walkAttributes(structVal.Type(), func(name string, field reflect.StructField, nested bool) {
+			tag := parseFuegoTag(field)
+			if !tag.hasDefault || isElementPath(name) || !isZeroAttribute(structVal, name) {
+				return
+			}
+
+			if _, err := setAttribute(structVal, name, false, tag.def); err != nil {
+				printError(errors.Wrapf(err, DefaultAttributeError, tag.def, name, structVal.Type().Name()))
+			}
+		})
+	}
+	return targets
+}
+
+// hasDefaultAttributes reports whether any struct attribute within the targets has a default to set
+func hasDefaultAttributes(targets interface{}) bool {
+	hasDefault := false
+	for _, target := range targetList(targets) {
+		structVal := reflect.Indirect(reflect.ValueOf(target))
+		if structVal.Kind() != reflect.Struct {
+			continue
+		}
+
+		walkAttributes(structVal.Type(), func(name string, field reflect.StructField, nested bool) {
+			if parseFuegoTag(field).hasDefault && !isElementPath(name) && isZeroAttribute(structVal, name) {
+				hasDefault = true
+			}
+		})
+	}
+	return hasDefault
+}
+
+// validateRequired checks that every `fuego:"required"` struct attribute has been set to a non zero value
+func validateRequired(structVal reflect.Value) error {
+	for x := 0; x < structVal.NumField(); x++ {
+		field := structVal.Type().Field(x)
+		if field.PkgPath == "" && parseFuegoTag(field).required && structVal.Field(x).IsZero() {
+			return errors.Errorf(RequiredAttributeError, attributeName(field), structVal.Type().Name())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type TaggedMath struct {
+	Offset int    `fuego:"name=offset,short=o,default=3,usage=added to every result, even negative ones"`
+	Label  string `fuego:"name=label,required"`
+}
+
+func (m TaggedMath) Add(a int, b int) int {
+	return a + b + m.Offset
+}
+
+type Mode string
+
+type TaggedDB struct {
+	Host string `fuego:"default=localhost"`
+	Port int    `fuego:"default=5432"`
+}
+
+type TaggedServer struct {
+	Mode Mode `fuego:"default=fast"`
+	DB   *TaggedDB
+}
+
+func (s TaggedServer) Describe() string {
+	return fmt.Sprintf("mode=%v db=%v:%v", s.Mode, s.DB.Host, s.DB.Port)
+}
+
+type TaggedProfile struct {
+	Config  string `fuego:"name=config"`
+	Timeout int    `fuego:"name=timeout"`
+}
+
+func (p TaggedProfile) Show() string {
+	return fmt.Sprintf("config=%v timeout=%v", p.Config, p.Timeout)
+}
+
+func TestParseFuegoTag(t *testing.T) {
+	field, _ := reflect.TypeOf(TaggedMath{}).FieldByName("Offset")
+	expected := fuegoTag{name: "offset", short: "o", def: "3", hasDefault: true, usage: "added to every result, even negative ones"}
+	if tag := parseFuegoTag(field); tag != expected {
+		t.Errorf("expected the tag %+v but got %+v", expected, tag)
+	}
+}
+
+func TestTags(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"offset": 10, "label": "config"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Name          string
+		Env           map[string]string
+		Targets       interface{}
+		Args          []string
+		Expected      interface{}
+		ExpectedError error
+	}{
+		{"Default", nil, &TaggedMath{}, []string{"Fuego.Tags.Default", "Add", "1", "2", "--label=x"}, 6, nil},
+		{"DefaultValueStruct", nil, TaggedMath{Label: "x"}, []string{"Fuego.Tags.DefaultValueStruct", "Add", "1", "2"}, 6, nil},
+		{"DefaultNotOverridingValue", nil, &TaggedMath{Offset: 1, Label: "x"}, []string{"Fuego.Tags.DefaultNotOverridingValue", "Add", "1", "2"}, 4, nil},
+		{"Name", nil, &TaggedMath{}, []string{"Fuego.Tags.Name", "Add", "1", "2", "--offset=5", "--label=x"}, 8, nil},
+		{"GoFieldName", nil, &TaggedMath{}, []string{"Fuego.Tags.GoFieldName", "Add", "1", "2", "--Offset=5", "--Label=x"}, 8, nil},
+		{"Short", nil, &TaggedMath{}, []string{"Fuego.Tags.Short", "Add", "1", "2", "-o=0", "--label=x"}, 3, nil},
+		{"NamedTypeDefault", nil, &TaggedServer{}, []string{"Fuego.Tags.NamedTypeDefault", "Describe"}, "mode=fast db=localhost:5432", nil},
+		{"NestedDefaultNotOverridingValue", nil, &TaggedServer{Mode: "slow", DB: &TaggedDB{Port: 1}}, []string{"Fuego.Tags.NestedDefaultNotOverridingValue", "Describe"}, "mode=slow db=localhost:1", nil},
+		{"FlagOverridesNestedDefault", nil, TaggedServer{}, []string{"Fuego.Tags.FlagOverridesNestedDefault", "Describe", "--DB.Host=db", "--Mode=safe"}, "mode=safe db=db:5432", nil},
+		{"Required", nil, &TaggedMath{}, []string{"Fuego.Tags.Required", "Add", "1", "2"}, nil, errors.Errorf(RequiredAttributeError, "label", "TaggedMath")},
+		{"Config", nil, &TaggedMath{}, []string{"Fuego.Tags.Config", "Add", "1", "2", "--config=" + configPath}, 13, nil},
+		{"OptionName", nil, &TaggedProfile{}, []string{"Fuego.Tags.OptionName", "Show", "--config=prod", "--timeout", "3"}, "config=prod timeout=3", nil},
+		{"Env", map[string]string{"TAGGEDMATH_OFFSET": "20", "TAGGEDMATH_LABEL": "env"}, &TaggedMath{}, []string{"Fuego.Tags.Env", "Add", "1", "2"}, 23, nil},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			for key, value := range testCase.Env {
+				t.Setenv(key, value)
+			}
+
+			os.Args = testCase.Args
+			values, err := Fuego(testCase.Targets)
+
+			if testCase.ExpectedError != nil {
+				if err == nil || !doErrorsMatch(testCase.ExpectedError, err) {
+					t.Errorf("Expected to receive the first error but instead the second error was returned: \n\t1) \"%v\"\n\t2) \"%v\"", testCase.ExpectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("Error is not expected but got %v", err)
+			} else if len(values) != 1 || !reflect.DeepEqual(values[0].Interface(), testCase.Expected) {
+				t.Errorf("expected %v but got %v", testCase.Expected, values)
+			}
+		})
+	}
+}
+
+func TestWriteHelpTags(t *testing.T) {
+	var out bytes.Buffer
+	if err := writeHelp(&out, &TaggedMath{}); err != nil {
+		t.Fatal(err)
+	}
+
+	help := strings.Join(strings.Fields(out.String()), " ")
+	for _, expected := range []string{
+		"--offset, -o=<int> added to every result, even negative ones (default 3) [env TAGGEDMATH_OFFSET]",
+		"--label=<string> TaggedMath attribute [required] [env TAGGEDMATH_LABEL]",
+	} {
+		if !strings.Contains(help, expected) {
+			t.Errorf("expected the help to contain %q but got:\n%v", expected, out.String())
+		}
+	}
+
+	out.Reset()
+	if err := writeHelp(&out, &TaggedServer{}); err != nil {
+		t.Fatal(err)
+	}
+	help = strings.Join(strings.Fields(out.String()), " ")
+	for _, expected := range []string{
+		"--Mode=<fuego.Mode> TaggedServer attribute (default fast)",
+		"--DB.Port=<int> TaggedServer attribute (default 5432)",
+	} {
+		if !strings.Contains(help, expected) {
+			t.Errorf("expected the help to contain %q but got:\n%v", expected, out.String())
+		}
+	}
+}
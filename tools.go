@@ -0,0 +1,112 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	InvalidToolCallError = "the tool call must be a JSON object with a \"name\" and the \"input\" (or \"arguments\") to call it with"
+	ToolNotFoundError    = "the tool \"%v\" does not exist"
+)
+
+// Tool describes a function or struct method as a tool definition for LLM agents, with the parameters as a JSON schema
+type Tool struct {
+	// Name is the name the tool is called by, ie. "AddInt" or "MyMath_Add"
+	Name string `json:"name"`
+	// Description is the doc comment of the function or method
+	Description string `json:"description,omitempty"`
+	// InputSchema is the JSON schema of the object of named parameters (and struct attributes) the tool is called with
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+// toolCall is a tool call made by an LLM agent, the input is accepted as "input" or as "arguments" (which may hold the JSON object as a string)
+type toolCall struct {
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// Tools describes every function and struct method within the targets as a tool definition.
+// Tools are called with named parameters so functions and methods whose parameter names are not known (the source is not available, see ParamNames) are left out.
+func Tools(targets interface{}) []Tool {
+	var tools []Tool
+	for _, cmd := range listCommands(targets) {
+		if !cmd.HasParamNames() {
+			continue
+		}
+		tools = append(tools, Tool{
+			Name:        toolName(cmd),
+			Description: cmd.Doc.Doc,
+			InputSchema: namedArgsSchema(cmd),
+		})
+	}
+	return tools
+}
+
+// CallTool executes the tool call JSON (ie. {"name": "MyMath_Add", "input": {"a": 5, "b": 3}}) against the targets using the same conversion and calling code as the command line.
+// Struct targets are copied for each call, and a trailing error returned by the target is returned as the error rather than as a result.
+func CallTool(targets interface{}, call []byte) ([]interface{}, error) {
+	return callTool(targets, call, &options{})
+}
+
+// callTool executes the tool call JSON against the targets with the options, which carry the middleware of an App
+func callTool(targets interface{}, call []byte, opts *options) ([]interface{}, error) {
+	var tc toolCall
+	if err := json.Unmarshal(call, &tc); err != nil || tc.Name == "" {
+		return nil, errors.New(InvalidToolCallError)
+	}
+
+	input := tc.Input
+	if input == nil {
+		input = tc.Arguments
+	}
+	// some agents pass the arguments as a string holding the JSON object
+	var encoded string
+	if err := json.Unmarshal(input, &encoded); err == nil {
+		input = json.RawMessage(encoded)
+	}
+
+	for _, cmd := range listCommands(targets) {
+		if toolName(cmd) != tc.Name {
+			continue
+		}
+
+		args, err := commandArgs(cmd, input)
+		if err != nil {
+			return nil, err
+		}
+
+		// the args of tool calls are never read from files or std in as the options are not those of the local command line
+		callOpts := *opts
+		callOpts.localArgs = false
+		values, err := dispatch(cloneTargets(targets), append([]string{os.Args[0], cmd.Name}, args...), &callOpts)
+		if err != nil {
+			return nil, err
+		}
+		return targetResults(values)
+	}
+
+	return nil, errors.Errorf(ToolNotFoundError, tc.Name)
+}
+
+// writeTools writes the tool definitions for the targets as indented JSON, used by --tools
+func writeTools(w io.Writer, targets interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(Tools(targets))
+}
+
+// toolName is the name of the tool for the command, agents only allow letters, digits, "_" and "-" so "MyMath.Add" becomes "MyMath_Add"
+func toolName(cmd command) string {
+	return strings.Replace(cmd.Name, ".", "_", -1)
+}
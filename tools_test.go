@@ -0,0 +1,104 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// Greet says hello to the name, repeating the greeting the number of times.
+func Greet(name string, times int) string {
+	greeting := ""
+	for x := 0; x < times; x++ {
+		greeting += "hello " + name + "!"
+	}
+	return greeting
+}
+
+func TestTools(t *testing.T) {
+	tools := Tools([]interface{}{Greet, MyMath{}})
+
+	out, err := json.Marshal(tools)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []map[string]interface{}
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]interface{}, len(got))
+	for x, tool := range got {
+		names[x] = tool["name"]
+	}
+	if expected := []interface{}{"Greet", "MyMath_Add", "MyMath_Multiply", "MyMath_Subtract"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("expected the tools %v but got %v", expected, names)
+	}
+
+	expectedGreet := map[string]interface{}{
+		"name":        "Greet",
+		"description": "Greet says hello to the name, repeating the greeting the number of times.",
+		"input_schema": map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": false,
+			"required":             []interface{}{"name", "times"},
+			"properties": map[string]interface{}{
+				"name":  map[string]interface{}{"type": "string"},
+				"times": map[string]interface{}{"type": "integer", "format": "int64"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got[0], expectedGreet) {
+		t.Errorf("expected the tool %v but got %v", expectedGreet, got[0])
+	}
+
+	schema := got[2]["input_schema"].(map[string]interface{})
+	if _, ok := schema["properties"].(map[string]interface{})["Offset"]; !ok || !reflect.DeepEqual(schema["required"], []interface{}{"a", "b"}) {
+		t.Errorf("expected the struct attribute to be an optional property and the context to be skipped but got %v", schema)
+	}
+}
+
+func TestCallTool(t *testing.T) {
+	targets := []interface{}{Greet, CheckPositive, &MyMath{Offset: 1}}
+
+	testCases := []struct {
+		Name            string
+		Call            string
+		ExpectedResults []interface{}
+		ExpectedError   error
+	}{
+		{"Input", `{"name": "Greet", "input": {"name": "bob", "times": 2}}`, []interface{}{"hello bob!hello bob!"}, nil},
+		{"EncodedArguments", `{"name": "MyMath_Add", "arguments": "{\"a\": 1, \"b\": 2, \"Offset\": 3}"}`, []interface{}{float64(6)}, nil},
+		{"AttributesNotShared", `{"name": "MyMath_Add", "input": {"a": 1, "b": 2}}`, []interface{}{float64(4)}, nil},
+		{"ArgsNotExpanded", `{"name": "Greet", "input": {"name": "@/etc/passwd", "times": 1}}`, []interface{}{"hello @/etc/passwd!"}, nil},
+		{"TargetError", `{"name": "CheckPositive", "input": {"a": -2}}`, nil, errors.New("the number is negative")},
+		{"MissingParameter", `{"name": "Greet", "input": {"name": "bob"}}`, nil, errors.Errorf(MissingArgumentError, "times", "Greet")},
+		{"InvalidParameter", `{"name": "Greet", "input": {"name": "bob", "times": "x"}}`, nil, errors.Errorf("%v: %v", ParameterListGenerationError, CannotConvertToDesiredValueTypeError)},
+		{"UnknownTool", `{"name": "MyMath.Add", "input": {}}`, nil, errors.Errorf(ToolNotFoundError, "MyMath.Add")},
+		{"InvalidCall", `{"input": {}}`, nil, errors.New(InvalidToolCallError)},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			results, err := CallTool(targets, []byte(testCase.Call))
+
+			if testCase.ExpectedError != nil {
+				if err == nil || !doErrorsMatch(testCase.ExpectedError, err) {
+					t.Errorf("Expected to receive the first error but instead the second error was returned: \n\t1) \"%v\"\n\t2) \"%v\"", testCase.ExpectedError, err)
+				}
+			} else if err != nil {
+				t.Errorf("Error is not expected but got %v", err)
+			} else if !reflect.DeepEqual(results, testCase.ExpectedResults) {
+				t.Errorf("expected the results %v but got %v", testCase.ExpectedResults, results)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+)
+
+// tracef writes a step of the --trace to the diagnostics writer when tracing is on
+func (opts *options) tracef(format string, args ...interface{}) {
+	if opts.trace {
+		fmt.Fprintf(opts.diagnostics(), "trace: "+format+"\n", args...)
+	}
+}
+
+// diagnostics returns the writer --trace and --time are written to, std err unless it is set on the options
+func (opts *options) diagnostics() io.Writer {
+	if opts.diagOut != nil {
+		return opts.diagOut
+	}
+	return os.Stderr
+}
+
+// traceCall wraps the call of the target when --trace is on, it traces the converted arguments the target is called with and the values it returns
+func (opts *options) traceCall(call Call, next func(Call) ([]reflect.Value, error)) ([]reflect.Value, error) {
+	opts.tracef("calling %v", call.Name())
+	for x, arg := range call.Args {
+		opts.tracef("  argument %v = %#v (%v)", x, arg.Interface(), arg.Type())
+	}
+
+	values, err := next(call)
+	if err != nil {
+		opts.tracef("%v failed: %v", call.Name(), err)
+		return values, err
+	}
+
+	opts.tracef("%v returned %v values", call.Name(), len(values))
+	for x, val := range values {
+		opts.tracef("  result %v = %#v (%v)", x, val.Interface(), val.Type())
+	}
+	return values, err
+}
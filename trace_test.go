@@ -0,0 +1,76 @@
+/*
+ * Created by Ilan Rasekh on 2019/9/17
+ * Copyright (c) 2019. All rights reserved.
+ */
+
+package fuego
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	PrintToStdOut = false
+	PrintToStdErr = false
+
+	testCases := []struct {
+		Name     string
+		Targets  interface{}
+		Args     []string
+		Expected []string
+	}{
+		{
+			"Function",
+			[]interface{}{AddInt, SubtractInt},
+			[]string{"Fuego.Trace.Function", "SubtractInt", "5", "3"},
+			[]string{
+				`trace: dispatching ["SubtractInt" "5" "3"] on the slice target []interface {}`,
+				`trace: "SubtractInt" matched the function SubtractInt`,
+				`trace: the function SubtractInt takes 2 parameters, 2 were passed in`,
+				`trace: calling SubtractInt`,
+				`trace:   argument 0 = 5 (int)`,
+				`trace:   argument 1 = 3 (int)`,
+				`trace: SubtractInt returned 1 values`,
+				`trace:   result 0 = 2 (int)`,
+			},
+		},
+		{
+			"Method",
+			&MyMath{},
+			[]string{"Fuego.Trace.Method", "Add", "--Offset=2", "1.5", "2", "--Offset=x"},
+			[]string{
+				`trace: the flag --Offset set MyMath.Offset = "2"`,
+				`trace: the flag --Offset could not set MyMath.Offset: cannot convert "x" to "float64" as needed`,
+				`trace: "Add" matched the method MyMath.Add`,
+				`trace:   argument 0 = 1.5 (float64)`,
+				`trace:   result 0 = 5.5 (float64)`,
+			},
+		},
+		{
+			"Panic",
+			DivideInt,
+			[]string{"Fuego.Trace.Panic", "1", "0"},
+			[]string{`trace: DivideInt failed: the call to "DivideInt" panicked: runtime error: integer divide by zero`},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			var out bytes.Buffer
+			opts := &options{diagOut: &out, localArgs: true}
+			args, err := opts.parse(append(testCase.Args, "--trace"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, _ = dispatch(testCase.Targets, args, opts)
+
+			for _, expected := range testCase.Expected {
+				if !strings.Contains(out.String(), expected+"\n") {
+					t.Errorf("expected the trace to contain %q but got:\n%v", expected, out.String())
+				}
+			}
+		})
+	}
+}